@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+
+	miniointernal "github.com/konstructio/kubernetes-toolkit/internal/minio"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var ensureBucketsCmdOptions *miniointernal.EnsureBucketsCmdOptions = &miniointernal.EnsureBucketsCmdOptions{}
+
+var ensureBucketsMinioConfig *miniointernal.Config = miniointernal.DefaultConfig()
+
+// ensureBucketsCmd represents the ensureBuckets command
+var ensureBucketsCmd = &cobra.Command{
+	Use:   "ensure-buckets",
+	Short: "Create minio buckets and apply their settings if needed",
+	Long: `Create the minio buckets of a spec file if they do not exist and apply their versioning,
+object lock, lifecycle expiration and bucket policy settings, reporting what changed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		spec, err := miniointernal.LoadBucketsSpec(afero.NewOsFs(), ensureBucketsCmdOptions.SpecFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = loadMinioConfig(cmd, ensureBucketsCmdOptions.ConfigFile, ensureBucketsMinioConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		minioClient, err := miniointernal.NewClient(ensureBucketsMinioConfig, ensureBucketsCmdOptions.KubeInClusterConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		changes, err := miniointernal.EnsureBuckets(minioClient, spec, ensureBucketsMinioConfig.Region, ensureBucketsCmdOptions.DryRun)
+		miniointernal.PrintChanges(os.Stdout, changes)
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ensureBucketsCmd)
+	ensureBucketsCmd.PersistentFlags().StringVar(&ensureBucketsCmdOptions.KubeInClusterConfig, "use-kubeconfig-in-cluster", "true", "Kube config type - in-cluster (default), set to false to use local")
+
+	ensureBucketsCmd.Flags().StringVarP(&ensureBucketsCmdOptions.SpecFile, "file", "f", ensureBucketsCmdOptions.SpecFile, "Path to the buckets spec file (required)")
+	err := ensureBucketsCmd.MarkFlagRequired("file")
+	if err != nil {
+		log.Fatal(err)
+	}
+	ensureBucketsCmd.Flags().BoolVar(&ensureBucketsCmdOptions.DryRun, "dry-run", false, "Report the changes without applying them")
+	addMinioFlags(ensureBucketsCmd, &ensureBucketsCmdOptions.ConfigFile, ensureBucketsMinioConfig)
+}
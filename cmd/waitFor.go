@@ -6,34 +6,75 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"strings"
+	"os"
 	"time"
 
-	"github.com/hashicorp/vault/api"
-	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	miniointernal "github.com/konstructio/kubernetes-toolkit/internal/minio"
+	"github.com/konstructio/kubernetes-toolkit/internal/plan"
+	"github.com/konstructio/kubernetes-toolkit/internal/waiter"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 )
 
 type WaitForCmdOptions struct {
-	Namespace           string
-	Name                string
-	Label               string
+	MinioConfigFile     string
 	Timeout             int64
 	KubeInClusterConfig string
+	PlanFile            string
 }
 
 var waitForCmdOptions *WaitForCmdOptions = &WaitForCmdOptions{}
 
+// The options of every subcommand are the options of a plan target of the same type
+var (
+	deploymentOptions         = waiter.NewObjectOptions(waiter.KindDeployment)
+	podOptions                = waiter.NewObjectOptions(waiter.KindPod)
+	statefulSetOptions        = waiter.NewObjectOptions(waiter.KindStatefulSet)
+	daemonSetOptions          = waiter.NewObjectOptions(waiter.KindDaemonSet)
+	pvcOptions                = waiter.NewObjectOptions(waiter.KindPVC)
+	jobOptions                = waiter.NewObjectOptions(waiter.KindJob)
+	endpointsOptions          = waiter.NewEndpointsOptions()
+	ingressOptions            = waiter.NewAddressOptions(waiter.KindIngress)
+	loadBalancerOptions       = waiter.NewAddressOptions(waiter.KindLoadBalancer)
+	crdOptions                = waiter.NewNamedOptions(waiter.KindCRD)
+	apiOptions                = &waiter.APIOptions{}
+	clusterSecretStoreOptions = waiter.NewNamedOptions(waiter.KindClusterSecretStore)
+	secretStoreOptions        = waiter.NewNamedOptions(waiter.KindSecretStore)
+	externalSecretOptions     = &waiter.ExternalSecretOptions{}
+	argoCDAppOptions          = waiter.NewArgoCDAppOptions()
+	issuerOptions             = waiter.NewNamedOptions(waiter.KindIssuer)
+	clusterIssuerOptions      = waiter.NewNamedOptions(waiter.KindClusterIssuer)
+	certificateOptions        = waiter.NewNamedOptions(waiter.KindCertificate)
+	httpOptions               = waiter.NewHTTPOptions()
+	secretOptions             = waiter.NewKeyOptions(waiter.KindSecret)
+	configMapOptions          = waiter.NewKeyOptions(waiter.KindConfigMap)
+	tcpOptions                = &waiter.TCPOptions{}
+	dnsOptions                = waiter.NewDNSOptions()
+	resourceOptions           = waiter.NewResourceOptions()
+	namespaceOptions          = waiter.NewNamespaceOptions()
+	minioBucketsOptions       = waiter.NewMinioBucketsOptions()
+	minioObjectOptions        = waiter.NewMinioObjectOptions()
+	vaultUnsealOptions        = waiter.NewVaultUnsealOptions()
+	vaultInitCompleteOptions  = waiter.NewVaultInitCompleteOptions()
+)
+
 // waitForCmd represents the waitFor command
 var waitForCmd = &cobra.Command{
 	Use:   "wait-for",
 	Short: "Wait on something in Kubernetes to be ready",
 	Long:  `Wait on a resource in Kubernetes to reach a ready state`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("waitFor called")
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Every command owns its --timeout-seconds flag, so that the default of one command is
+		// not overwritten by the commands registered after it
+		if cmd.Flags().Lookup("timeout-seconds") == nil {
+			return
+		}
+		timeout, err := cmd.Flags().GetInt64("timeout-seconds")
+		if err != nil {
+			log.Fatal(err)
+		}
+		waitForCmdOptions.Timeout = timeout
 	},
 }
 
@@ -43,21 +84,7 @@ var waitForDeploymentCmd = &cobra.Command{
 	Short: "Wait for a Deployment to be ready",
 	Long:  `Wait for a Deployment to be ready`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(waitForCmdOptions)
-		label := strings.Split(waitForCmdOptions.Label, "=")
-		if len(label) != 2 {
-			log.Fatalf("please check the provided label: %s", waitForCmdOptions.Label)
-		}
-
-		_, clientset, _ := kubernetes.CreateKubeConfig(waitForCmdOptions.KubeInClusterConfig)
-		deployment, err := kubernetes.ReturnDeploymentObject(&clientset, label[0], label[1], waitForCmdOptions.Namespace, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error retrieving deployment object: %s", err)
-		}
-		_, err = kubernetes.WaitForDeploymentReady(&clientset, deployment, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error waiting for deployment object: %s", err)
-		}
+		waitFor(deploymentOptions)
 	},
 }
 
@@ -67,20 +94,7 @@ var waitForPodCmd = &cobra.Command{
 	Short: "Wait for a Pod to be ready",
 	Long:  `Wait for a Pod to be ready`,
 	Run: func(cmd *cobra.Command, args []string) {
-		label := strings.Split(waitForCmdOptions.Label, "=")
-		if len(label) != 2 {
-			log.Fatalf("please check the provided label: %s", waitForCmdOptions.Label)
-		}
-
-		_, clientset, _ := kubernetes.CreateKubeConfig(waitForCmdOptions.KubeInClusterConfig)
-		pod, err := kubernetes.ReturnPodObject(&clientset, label[0], label[1], waitForCmdOptions.Namespace, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error retrieving pod object: %s", err)
-		}
-		_, err = kubernetes.WaitForPodReady(&clientset, pod, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error waiting for pod object: %s", err)
-		}
+		waitFor(podOptions)
 	},
 }
 
@@ -90,20 +104,91 @@ var waitForStatefulSetCmd = &cobra.Command{
 	Short: "Wait for a StatefulSet to be ready",
 	Long:  `Wait for a StatefulSet to be ready`,
 	Run: func(cmd *cobra.Command, args []string) {
-		label := strings.Split(waitForCmdOptions.Label, "=")
-		if len(label) != 2 {
-			log.Fatalf("please check the provided label: %s", waitForCmdOptions.Label)
-		}
+		waitFor(statefulSetOptions)
+	},
+}
 
-		_, clientset, _ := kubernetes.CreateKubeConfig(waitForCmdOptions.KubeInClusterConfig)
-		sts, err := kubernetes.ReturnStatefulSetObject(&clientset, label[0], label[1], waitForCmdOptions.Namespace, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error retrieving statefulset object: %s", err)
-		}
-		_, err = kubernetes.WaitForStatefulSetReady(&clientset, sts, waitForCmdOptions.Timeout, false)
-		if err != nil {
-			log.Fatalf("error waiting for statefulset object: %s", err)
-		}
+// waitForDaemonSetCmd represents the waitForDaemonSetCmd command
+var waitForDaemonSetCmd = &cobra.Command{
+	Use:   "daemonset",
+	Short: "Wait for a DaemonSet to be ready",
+	Long:  `Wait for a DaemonSet to be rolled out and ready on every scheduled node`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(daemonSetOptions)
+	},
+}
+
+// waitForPersistentVolumeClaimCmd represents the waitForPersistentVolumeClaimCmd command
+var waitForPersistentVolumeClaimCmd = &cobra.Command{
+	Use:   "pvc",
+	Short: "Wait for a PersistentVolumeClaim to be bound",
+	Long:  `Wait for a PersistentVolumeClaim to be bound, reporting provisioning events on timeout`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(pvcOptions)
+	},
+}
+
+// waitForJobCmd represents the waitForJobCmd command
+var waitForJobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Wait for a Job to complete",
+	Long:  `Wait for a Job to complete, failing as soon as the Job fails or exhausts its backoffLimit`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(jobOptions)
+	},
+}
+
+// waitForEndpointsCmd represents the waitForEndpointsCmd command
+var waitForEndpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "Wait for a Service to have ready endpoints",
+	Long:  `Wait for the EndpointSlices of a Service to contain a minimum number of ready addresses`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(endpointsOptions)
+	},
+}
+
+// waitForIngressCmd represents the waitForIngressCmd command
+var waitForIngressCmd = &cobra.Command{
+	Use:   "ingress",
+	Short: "Wait for an Ingress to be assigned an address",
+	Long: `Wait for an Ingress to be assigned a hostname or IP by its ingress controller,
+print it and optionally write it to a ConfigMap key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(ingressOptions)
+		fmt.Println(ingressOptions.Address())
+	},
+}
+
+// waitForLoadBalancerCmd represents the waitForLoadBalancerCmd command
+var waitForLoadBalancerCmd = &cobra.Command{
+	Use:   "loadbalancer",
+	Short: "Wait for a LoadBalancer Service to be assigned an address",
+	Long: `Wait for a Service of type LoadBalancer to be assigned a hostname or IP,
+print it and optionally write it to a ConfigMap key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(loadBalancerOptions)
+		fmt.Println(loadBalancerOptions.Address())
+	},
+}
+
+// waitForCustomResourceDefinitionCmd represents the waitForCustomResourceDefinitionCmd command
+var waitForCustomResourceDefinitionCmd = &cobra.Command{
+	Use:   "crd",
+	Short: "Wait for a CustomResourceDefinition to be established",
+	Long:  `Wait for a CustomResourceDefinition to have its names accepted and to be established`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(crdOptions)
+	},
+}
+
+// waitForAPICmd represents the waitForAPICmd command
+var waitForAPICmd = &cobra.Command{
+	Use:   "api",
+	Short: "Wait for an API group version to be served",
+	Long:  `Wait for API discovery to serve the resources of a group version, e.g. external-secrets.io/v1beta1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(apiOptions)
 	},
 }
 
@@ -113,11 +198,137 @@ var waitForClusterSecretStoreCmd = &cobra.Command{
 	Short: "Wait for an External Secrets Operator cluster secret store to be ready",
 	Long:  `Wait for an External Secrets Operator cluster secret store to be ready`,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, clientset, _ := kubernetes.CreateKubeConfig(waitForCmdOptions.KubeInClusterConfig)
-		err := kubernetes.WaitForClusterSecretStoreReady(&clientset, waitForCmdOptions.Name, waitForCmdOptions.Timeout)
-		if err != nil {
-			log.Fatalf("error waiting for ClusterSecretStore object: %s", err)
-		}
+		waitFor(clusterSecretStoreOptions)
+	},
+}
+
+// waitForSecretStoreCmd represents the waitForSecretStoreCmd command
+var waitForSecretStoreCmd = &cobra.Command{
+	Use:   "secret-store",
+	Short: "Wait for an External Secrets Operator secret store to be ready",
+	Long:  `Wait for a namespaced External Secrets Operator secret store to be ready`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(secretStoreOptions)
+	},
+}
+
+// waitForExternalSecretCmd represents the waitForExternalSecretCmd command
+var waitForExternalSecretCmd = &cobra.Command{
+	Use:   "external-secret",
+	Short: "Wait for an External Secrets Operator external secret to be synced",
+	Long: `Wait for an External Secrets Operator external secret to be synced, and optionally
+for its target Secret to exist and to contain a set of keys`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(externalSecretOptions)
+	},
+}
+
+// waitForArgoCDAppCmd represents the waitForArgoCDAppCmd command
+var waitForArgoCDAppCmd = &cobra.Command{
+	Use:   "argocd-app",
+	Short: "Wait for an Argo CD Application to be synced and healthy",
+	Long: `Wait for an Argo CD Application to be synced and to reach a target health status,
+reporting the health of any resource that is not healthy`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(argoCDAppOptions)
+	},
+}
+
+// waitForIssuerCmd represents the waitForIssuerCmd command
+var waitForIssuerCmd = &cobra.Command{
+	Use:   "issuer",
+	Short: "Wait for a cert-manager Issuer to be ready",
+	Long: `Wait for a cert-manager Issuer to be ready, including the registration of its
+ACME account or the validation of its CA secret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(issuerOptions)
+	},
+}
+
+// waitForClusterIssuerCmd represents the waitForClusterIssuerCmd command
+var waitForClusterIssuerCmd = &cobra.Command{
+	Use:   "cluster-issuer",
+	Short: "Wait for a cert-manager ClusterIssuer to be ready",
+	Long: `Wait for a cert-manager ClusterIssuer to be ready, including the registration of its
+ACME account or the validation of its CA secret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(clusterIssuerOptions)
+	},
+}
+
+// waitForHTTPCmd represents the waitForHTTPCmd command
+var waitForHTTPCmd = &cobra.Command{
+	Use:   "http",
+	Short: "Wait for an HTTP endpoint to answer",
+	Long: `Wait for an HTTP endpoint to return an expected status code and optionally a body
+matching a regex, trusting a CA bundle from a Secret or ConfigMap and presenting a client certificate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(httpOptions)
+	},
+}
+
+// waitForSecretCmd represents the waitForSecretCmd command
+var waitForSecretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Wait for a Secret key to be set",
+	Long: `Wait for a Secret to contain a key, optionally set to an expected value
+or to a value matching a regex`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(secretOptions)
+	},
+}
+
+// waitForConfigMapCmd represents the waitForConfigMapCmd command
+var waitForConfigMapCmd = &cobra.Command{
+	Use:   "configmap",
+	Short: "Wait for a ConfigMap key to be set",
+	Long: `Wait for a ConfigMap to contain a key, optionally set to an expected value
+or to a value matching a regex`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(configMapOptions)
+	},
+}
+
+// waitForTCPCmd represents the waitForTCPCmd command
+var waitForTCPCmd = &cobra.Command{
+	Use:   "tcp",
+	Short: "Wait for a TCP port to accept connections",
+	Long:  `Wait for a host:port address, e.g. a database, to accept TCP connections`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(tcpOptions)
+	},
+}
+
+// waitForDNSCmd represents the waitForDNSCmd command
+var waitForDNSCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Wait for a DNS name to resolve",
+	Long: `Wait for a DNS name to resolve to a record type and optionally to expected values,
+using the system resolver or a specific nameserver`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(dnsOptions)
+	},
+}
+
+// waitForResourceCmd represents the waitForResourceCmd command
+var waitForResourceCmd = &cobra.Command{
+	Use:   "resource",
+	Short: "Wait for any resource to reach a condition",
+	Long: `Wait for any resource, including custom resources, to reach a status condition
+or for a JSONPath expression to return an expected value`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(resourceOptions)
+	},
+}
+
+// waitForNamespaceCmd represents the waitForNamespaceCmd command
+var waitForNamespaceCmd = &cobra.Command{
+	Use:   "namespace",
+	Short: "Wait for a Namespace to be active or deleted",
+	Long: `Wait for a Namespace to be active, or with --for=delete to be fully deleted,
+reporting the content and finalizers that remain if it is stuck terminating`,
+	Run: func(cmd *cobra.Command, args []string) {
+		waitFor(namespaceOptions)
 	},
 }
 
@@ -125,44 +336,29 @@ var waitForClusterSecretStoreCmd = &cobra.Command{
 var waitForMinioBucketCmd = &cobra.Command{
 	Use:   "minio-buckets",
 	Short: "Wait for all minio buckets to be created",
-	Long:  `Wait for all minio buckets to be created`,
+	Long: `Wait for all minio buckets to be created, reading the endpoint, TLS settings, region and
+buckets from flags or a config file, and the credentials from a Secret or environment variables`,
 	Run: func(cmd *cobra.Command, args []string) {
-		minioEndpoint := "minio.minio.svc.cluster.local:9000"
-		minioDefaultUsername := "k-ray"
-		minioDefaultPassword := "feedkraystars"
-		// Initialize minio client object.
-		minioClient, err := minio.New(minioEndpoint, &minio.Options{
-			Creds:  credentials.NewStaticV4(minioDefaultUsername, minioDefaultPassword, ""),
-			Secure: false,
-			Region: "us-k3d-1",
-		})
+		err := loadMinioConfig(cmd, waitForCmdOptions.MinioConfigFile, &minioBucketsOptions.Config)
 		if err != nil {
-			log.Fatal("Error creating Minio client: %s", err)
+			log.Fatal(err)
 		}
+		waitFor(minioBucketsOptions)
+	},
+}
 
-		buckets := []string{"chartmuseum", "argo-artifacts", "gitlab-backup", "kubefirst-state-store", "vault-backend"}
-
-		// loop until all buckets exist
-		for {
-			allExist := true
-			for _, bucket := range buckets {
-				found, err := minioClient.BucketExists(context.Background(), bucket)
-				if err != nil {
-					log.Fatalln("Error checking bucket existence:", err)
-				}
-				if !found {
-					allExist = false
-					break
-				}
-			}
-			if allExist {
-				break
-			}
-			fmt.Println("waiting for all minio buckets to exist...")
-			time.Sleep(5 * time.Second)
+// waitForObjectCmd represents the waitForObjectCmd command
+var waitForObjectCmd = &cobra.Command{
+	Use:   "object",
+	Short: "Wait for an object to exist in a minio bucket",
+	Long: `Wait for an object key, or any object under a prefix, to exist in a minio bucket,
+optionally with a minimum size or modified after a point in time`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := loadMinioConfig(cmd, waitForCmdOptions.MinioConfigFile, &minioObjectOptions.Config)
+		if err != nil {
+			log.Fatal(err)
 		}
-
-		fmt.Println("all minio buckets created")
+		waitFor(minioObjectOptions)
 	},
 }
 
@@ -170,20 +366,10 @@ var waitForMinioBucketCmd = &cobra.Command{
 var waitForVaultUnsealCmd = &cobra.Command{
 	Use:   "vault-unseal",
 	Short: "Wait for vault to be unsealed",
-	Long:  `Wait for vault to be unsealed`,
+	Long: `Wait for every vault pod, or the vault instances at the provided addresses, to report
+that they are unsealed through sys/seal-status and sys/health, optionally only requiring a quorum`,
 	Run: func(cmd *cobra.Command, args []string) {
-
-		for {
-			vaultRootTokenLookup, err := kubernetes.ReadSecretV2("true", "vault", "vault-unseal-secret")
-			if err != nil {
-				fmt.Println(err)
-				time.Sleep(5 * time.Second)
-			}
-			if vaultRootTokenLookup["root-token"] != "" {
-				break
-			}
-		}
-		fmt.Println("vault successfully unsealed")
+		waitFor(vaultUnsealOptions)
 	},
 }
 
@@ -191,30 +377,10 @@ var waitForVaultUnsealCmd = &cobra.Command{
 var waitForVaultInitCompleteCmd = &cobra.Command{
 	Use:   "vault-init-complete",
 	Short: "Wait for vault to be configured with terraform",
-	Long:  `Wait for vault to be configured with terraform`,
+	Long: `Wait for vault to be configured with terraform, which is detected by reading one or more
+secrets with a token or with the Kubernetes auth method`,
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg := api.DefaultConfig()
-		cfg.Address = "http://vault.vault.svc.cluster.local:8200"
-
-		client, err := api.NewClient(cfg)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		for {
-			// Read the secret from the Vault server
-			secret, err := client.Logical().Read("secret/data/development/metaphor")
-			if err == nil {
-				// Check if the secret was found
-				if secret != nil {
-					break
-				}
-			}
-
-			fmt.Println("Waiting for vault to terraform to apply, sleeping 5 seconds")
-			time.Sleep(5 * time.Second)
-		}
-		fmt.Println("vault successfully hydrated")
+		waitFor(vaultInitCompleteOptions)
 	},
 }
 
@@ -224,89 +390,452 @@ var waitForCertificateCmd = &cobra.Command{
 	Short: "Wait for cert-manager Certificate creation",
 	Long:  `Wait for cert-manager Certificate creation`,
 	Run: func(cmd *cobra.Command, args []string) {
-		restConfig, _, _ := kubernetes.CreateKubeConfig(waitForCmdOptions.KubeInClusterConfig)
-		err := kubernetes.WaitForCertificateReady(restConfig, waitForCmdOptions.Namespace, waitForCmdOptions.Name, waitForCmdOptions.Timeout)
+		waitFor(certificateOptions)
+	},
+}
+
+// waitForPlanCmd represents the waitForPlan command
+var waitForPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Wait for all targets in a plan file",
+	Long: `Wait for all targets in a plan file concurrently, honoring dependsOn ordering
+between targets, and print a summary once every target has finished`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := plan.Load(afero.NewOsFs(), waitForCmdOptions.PlanFile, waitForCmdOptions.Timeout)
 		if err != nil {
-			log.Fatalf("error waiting for Certificate object: %s", err)
+			log.Fatal(err)
+		}
+
+		// The clients are shared by every target, a kubeconfig that cannot be loaded fails
+		// the targets that need it
+		clients := waiter.NewClients(waitForCmdOptions.KubeInClusterConfig)
+		results := p.Execute(func(ctx context.Context, t plan.Target) error {
+			return t.Waiter.Wait(ctx, clients)
+		})
+		plan.PrintSummary(os.Stdout, results)
+		if plan.Failed(results) {
+			os.Exit(1)
 		}
 	},
 }
 
+// waitContext returns a context that expires once --timeout-seconds have passed, so that
+// finding an object and waiting for it to be ready share the same deadline
+func (o *WaitForCmdOptions) waitContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(o.Timeout)*time.Second)
+}
+
+// waitFor validates the options of a subcommand and waits on them until --timeout-seconds have passed
+func waitFor(w waiter.Waiter) {
+	if err := w.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := waitForCmdOptions.waitContext()
+	defer cancel()
+	err := w.Wait(ctx, waiter.NewClients(waitForCmdOptions.KubeInClusterConfig))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// addObjectFlags adds the flags used to select the objects to wait on and to choose between
+// waiting for readiness or deletion
+func addObjectFlags(cmd *cobra.Command, o *waiter.ObjectOptions) {
+	cmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace containing the resource (required)")
+	err := cmd.MarkFlagRequired("namespace")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Resource name")
+	cmd.Flags().StringVar(&o.Label, "label", o.Label, "Label selector to match the resource, e.g. app.kubernetes.io/name=vault,tier in (web, api)")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Field selector to match the resource, e.g. status.phase=Running")
+	addForFlag(cmd, &o.For)
+}
+
+// addMultipleObjectFlags adds the flags used to wait on more than one matching object
+func addMultipleObjectFlags(cmd *cobra.Command, o *waiter.ObjectOptions) {
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Wait for every matching resource to be ready")
+	cmd.Flags().IntVar(&o.MinCount, "min-count", o.MinCount, "Wait for at least this many matching resources to exist and be ready")
+}
+
+// addForFlag adds the flag used to choose between waiting for readiness or deletion
+func addForFlag(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "for", waiter.ForReady, "What to wait for, ready or delete - ready (default)")
+}
+
+// addNameFlags adds the flags used to name the object to wait on, and its namespace for
+// namespaced kinds
+func addNameFlags(cmd *cobra.Command, o *waiter.NamedOptions, namespaced bool) {
+	if namespaced {
+		cmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace containing the resource (required)")
+		err := cmd.MarkFlagRequired("namespace")
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Resource name (required)")
+	err := cmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+}
+
+// addAddressFlags adds the flags used to wait on an address and write it to a ConfigMap
+func addAddressFlags(cmd *cobra.Command, o *waiter.AddressOptions) {
+	cmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace containing the resource (required)")
+	err := cmd.MarkFlagRequired("namespace")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Resource name (required)")
+	err = cmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.ConfigMapName, "configmap-name", o.ConfigMapName, "Existing ConfigMap to write the address to")
+	cmd.Flags().StringVar(&o.ConfigMapNamespace, "configmap-namespace", o.ConfigMapNamespace, "Namespace of the ConfigMap, defaults to the resource namespace")
+	cmd.Flags().StringVar(&o.ConfigMapKey, "configmap-key", o.ConfigMapKey, "ConfigMap key to write the address to - address (default)")
+	cmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+}
+
+// addMinioFlags adds the flags used to connect to minio, which override the config file
+func addMinioFlags(cmd *cobra.Command, configFile *string, c *miniointernal.Config) {
+	cmd.Flags().StringVar(configFile, "config", *configFile, "Minio config file, overridden by flags")
+	cmd.Flags().StringVar(&c.Endpoint, "endpoint", c.Endpoint, "Minio endpoint as host:port")
+	cmd.Flags().StringVar(&c.Region, "region", c.Region, "Minio region")
+	cmd.Flags().BoolVar(&c.Secure, "secure", c.Secure, "Connect to minio with TLS")
+	cmd.Flags().BoolVar(&c.InsecureSkipVerify, "insecure-skip-verify", c.InsecureSkipVerify, "Skip verification of the minio server certificate")
+	cmd.Flags().StringVar(&c.CAFile, "ca-file", c.CAFile, "Path to a PEM encoded CA bundle to trust")
+	cmd.Flags().StringVar(&c.Credentials.SecretNamespace, "credentials-secret-namespace", c.Credentials.SecretNamespace, "Namespace of the Secret containing the credentials")
+	cmd.Flags().StringVar(&c.Credentials.SecretName, "credentials-secret-name", c.Credentials.SecretName, "Secret containing the credentials, environment variables are used otherwise")
+	cmd.Flags().StringVar(&c.Credentials.AccessKeyKey, "access-key-key", c.Credentials.AccessKeyKey, "Key of the access key in the Secret")
+	cmd.Flags().StringVar(&c.Credentials.SecretKeyKey, "secret-key-key", c.Credentials.SecretKeyKey, "Key of the secret key in the Secret")
+	cmd.Flags().StringVar(&c.Credentials.AccessKeyEnv, "access-key-env", c.Credentials.AccessKeyEnv, "Environment variable containing the access key")
+	cmd.Flags().StringVar(&c.Credentials.SecretKeyEnv, "secret-key-env", c.Credentials.SecretKeyEnv, "Environment variable containing the secret key")
+}
+
+// loadMinioConfig replaces c with the config file, when one is provided, overridden by the flags that were set
+func loadMinioConfig(cmd *cobra.Command, configFile string, c *miniointernal.Config) error {
+	if configFile == "" {
+		return nil
+	}
+	minioConfig, err := miniointernal.LoadConfig(afero.NewOsFs(), configFile)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	for name, override := range map[string]func(){
+		"endpoint":                     func() { minioConfig.Endpoint = c.Endpoint },
+		"region":                       func() { minioConfig.Region = c.Region },
+		"secure":                       func() { minioConfig.Secure = c.Secure },
+		"insecure-skip-verify":         func() { minioConfig.InsecureSkipVerify = c.InsecureSkipVerify },
+		"ca-file":                      func() { minioConfig.CAFile = c.CAFile },
+		"buckets":                      func() { minioConfig.Buckets = c.Buckets },
+		"credentials-secret-namespace": func() { minioConfig.Credentials.SecretNamespace = c.Credentials.SecretNamespace },
+		"credentials-secret-name":      func() { minioConfig.Credentials.SecretName = c.Credentials.SecretName },
+		"access-key-key":               func() { minioConfig.Credentials.AccessKeyKey = c.Credentials.AccessKeyKey },
+		"secret-key-key":               func() { minioConfig.Credentials.SecretKeyKey = c.Credentials.SecretKeyKey },
+		"access-key-env":               func() { minioConfig.Credentials.AccessKeyEnv = c.Credentials.AccessKeyEnv },
+		"secret-key-env":               func() { minioConfig.Credentials.SecretKeyEnv = c.Credentials.SecretKeyEnv },
+	} {
+		if flags.Lookup(name) != nil && flags.Changed(name) {
+			override()
+		}
+	}
+	*c = *minioConfig
+	return nil
+}
+
+// addKeyFlags adds the flags used to select a Secret or ConfigMap key to wait on
+func addKeyFlags(cmd *cobra.Command, o *waiter.KeyOptions) {
+	cmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace containing the resource (required)")
+	err := cmd.MarkFlagRequired("namespace")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Resource name (required)")
+	err = cmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.Key, "key", o.Key, "Key to wait for (required)")
+	err = cmd.MarkFlagRequired("key")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Flags().StringVar(&o.Value, "value", o.Value, "Value the key must be set to")
+	cmd.Flags().StringVar(&o.ValueRegex, "value-regex", o.ValueRegex, "Regex the value of the key must match")
+	cmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+}
+
 func init() {
 	rootCmd.AddCommand(waitForCmd)
 	waitForCmd.PersistentFlags().StringVar(&waitForCmdOptions.KubeInClusterConfig, "use-kubeconfig-in-cluster", "true", "Kube config type - in-cluster (default), set to false to use local")
 
 	// waitForDeploymentCmd
 	waitForCmd.AddCommand(waitForDeploymentCmd)
-	waitForDeploymentCmd.Flags().StringVar(&waitForCmdOptions.Namespace, "namespace", waitForCmdOptions.Namespace, "Namespace containing the resource (required)")
-	err := waitForDeploymentCmd.MarkFlagRequired("namespace")
-	if err != nil {
-		log.Fatal(err)
-	}
-	waitForDeploymentCmd.Flags().StringVar(&waitForCmdOptions.Label, "label", waitForCmdOptions.Label, "Label to select the resource in the form key=value (required)")
-	err = waitForDeploymentCmd.MarkFlagRequired("label")
+	addObjectFlags(waitForDeploymentCmd, deploymentOptions)
+	addMultipleObjectFlags(waitForDeploymentCmd, deploymentOptions)
+	waitForDeploymentCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForNamespaceCmd
+	waitForCmd.AddCommand(waitForNamespaceCmd)
+	waitForNamespaceCmd.Flags().StringVar(&namespaceOptions.Name, "name", namespaceOptions.Name, "Namespace name (required)")
+	err := waitForNamespaceCmd.MarkFlagRequired("name")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForDeploymentCmd.Flags().Int64Var(&waitForCmdOptions.Timeout, "timeout-seconds", 60, "Timeout seconds - 60 (default)")
+	addForFlag(waitForNamespaceCmd, &namespaceOptions.For)
+	waitForNamespaceCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
 
 	// waitForMinioBucketCmd
 	waitForCmd.AddCommand(waitForMinioBucketCmd)
+	addMinioFlags(waitForMinioBucketCmd, &waitForCmdOptions.MinioConfigFile, &minioBucketsOptions.Config)
+	waitForMinioBucketCmd.Flags().StringSliceVar(&minioBucketsOptions.Buckets, "buckets", minioBucketsOptions.Buckets, "Buckets to wait for, can be repeated or comma separated")
+	waitForMinioBucketCmd.Flags().Int64("timeout-seconds", 300, "Timeout seconds - 300 (default)")
+
+	// waitForObjectCmd
+	waitForCmd.AddCommand(waitForObjectCmd)
+	waitForObjectCmd.Flags().StringVar(&minioObjectOptions.Bucket, "bucket", minioObjectOptions.Bucket, "Bucket containing the object (required)")
+	err = waitForObjectCmd.MarkFlagRequired("bucket")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForObjectCmd.Flags().StringVar(&minioObjectOptions.Key, "key", minioObjectOptions.Key, "Key of the object")
+	waitForObjectCmd.Flags().StringVar(&minioObjectOptions.Prefix, "prefix", minioObjectOptions.Prefix, "Prefix under which any object matches")
+	waitForObjectCmd.Flags().Int64Var(&minioObjectOptions.MinSize, "min-size", minioObjectOptions.MinSize, "Minimum size of the object in bytes")
+	waitForObjectCmd.Flags().StringVar(&minioObjectOptions.NewerThan, "newer-than", minioObjectOptions.NewerThan, "RFC3339 timestamp, or duration before now such as 1h, the object must be modified after")
+	addMinioFlags(waitForObjectCmd, &waitForCmdOptions.MinioConfigFile, &minioObjectOptions.Config)
+	waitForObjectCmd.Flags().Int64("timeout-seconds", 300, "Timeout seconds - 300 (default)")
 
 	// waitForVaultUnsealCmd
 	waitForCmd.AddCommand(waitForVaultUnsealCmd)
+	waitForVaultUnsealCmd.Flags().StringSliceVar(&vaultUnsealOptions.Addresses, "address", vaultUnsealOptions.Addresses, "Vault addresses to check instead of the vault pods, can be repeated or comma separated")
+	waitForVaultUnsealCmd.Flags().StringVar(&vaultUnsealOptions.Namespace, "namespace", vaultUnsealOptions.Namespace, "Namespace containing the vault pods - vault (default)")
+	waitForVaultUnsealCmd.Flags().StringVar(&vaultUnsealOptions.Label, "label", vaultUnsealOptions.Label, "Label selector matching the vault pods")
+	waitForVaultUnsealCmd.Flags().StringVar(&vaultUnsealOptions.Scheme, "scheme", vaultUnsealOptions.Scheme, "Scheme used to reach the vault pods - http (default)")
+	waitForVaultUnsealCmd.Flags().IntVar(&vaultUnsealOptions.Port, "port", vaultUnsealOptions.Port, "Port used to reach the vault pods - 8200 (default)")
+	waitForVaultUnsealCmd.Flags().BoolVar(&vaultUnsealOptions.InsecureSkipVerify, "insecure-skip-verify", vaultUnsealOptions.InsecureSkipVerify, "Skip verification of the vault server certificate")
+	waitForVaultUnsealCmd.Flags().IntVar(&vaultUnsealOptions.Quorum, "quorum", vaultUnsealOptions.Quorum, "Number of unsealed instances required, all instances when 0 - 0 (default)")
+	waitForVaultUnsealCmd.Flags().Int64("timeout-seconds", 300, "Timeout seconds - 300 (default)")
 
 	// waitForVaultInitCompleteCmd
 	waitForCmd.AddCommand(waitForVaultInitCompleteCmd)
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.Address, "address", vaultInitCompleteOptions.Address, "Vault address")
+	waitForVaultInitCompleteCmd.Flags().StringSliceVar(&vaultInitCompleteOptions.Paths, "path", vaultInitCompleteOptions.Paths, "Secret paths that must be readable, can be repeated or comma separated")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.KVMount, "kv-mount", vaultInitCompleteOptions.KVMount, "Mount of the KV secrets engine - secret (default)")
+	waitForVaultInitCompleteCmd.Flags().IntVar(&vaultInitCompleteOptions.KVVersion, "kv-version", vaultInitCompleteOptions.KVVersion, "Version of the KV secrets engine, 1 or 2 - 2 (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.AuthMethod, "auth-method", vaultInitCompleteOptions.AuthMethod, "Auth method, token or kubernetes - token (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.TokenEnv, "token-env", vaultInitCompleteOptions.TokenEnv, "Environment variable containing the token - VAULT_TOKEN (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.TokenFile, "token-file", vaultInitCompleteOptions.TokenFile, "File containing the token")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.TokenSecretNamespace, "token-secret-namespace", vaultInitCompleteOptions.TokenSecretNamespace, "Namespace of the Secret containing the token - vault (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.TokenSecretName, "token-secret-name", vaultInitCompleteOptions.TokenSecretName, "Secret containing the token, e.g. vault-unseal-secret")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.TokenSecretKey, "token-secret-key", vaultInitCompleteOptions.TokenSecretKey, "Key of the token in the Secret - root-token (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.KubernetesRole, "kubernetes-role", vaultInitCompleteOptions.KubernetesRole, "Role used with the kubernetes auth method")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.KubernetesMount, "kubernetes-mount", vaultInitCompleteOptions.KubernetesMount, "Mount of the kubernetes auth method - kubernetes (default)")
+	waitForVaultInitCompleteCmd.Flags().StringVar(&vaultInitCompleteOptions.ServiceAccountTokenFile, "service-account-token-file", vaultInitCompleteOptions.ServiceAccountTokenFile, "File containing the service account JWT used with the kubernetes auth method")
+	waitForVaultInitCompleteCmd.Flags().Int64("timeout-seconds", 300, "Timeout seconds - 300 (default)")
 
 	// waitForPodCmd
 	waitForCmd.AddCommand(waitForPodCmd)
-	waitForPodCmd.Flags().StringVar(&waitForCmdOptions.Namespace, "namespace", waitForCmdOptions.Namespace, "Namespace containing the resource (required)")
-	err = waitForPodCmd.MarkFlagRequired("namespace")
+	addObjectFlags(waitForPodCmd, podOptions)
+	addMultipleObjectFlags(waitForPodCmd, podOptions)
+	waitForPodCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForStatefulSetCmd
+	waitForCmd.AddCommand(waitForStatefulSetCmd)
+	addObjectFlags(waitForStatefulSetCmd, statefulSetOptions)
+	addMultipleObjectFlags(waitForStatefulSetCmd, statefulSetOptions)
+	waitForStatefulSetCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForDaemonSetCmd
+	waitForCmd.AddCommand(waitForDaemonSetCmd)
+	addObjectFlags(waitForDaemonSetCmd, daemonSetOptions)
+	addMultipleObjectFlags(waitForDaemonSetCmd, daemonSetOptions)
+	waitForDaemonSetCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForPersistentVolumeClaimCmd
+	waitForCmd.AddCommand(waitForPersistentVolumeClaimCmd)
+	addObjectFlags(waitForPersistentVolumeClaimCmd, pvcOptions)
+	waitForPersistentVolumeClaimCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForJobCmd
+	waitForCmd.AddCommand(waitForJobCmd)
+	addObjectFlags(waitForJobCmd, jobOptions)
+	waitForJobCmd.Flags().Int64Var(&jobOptions.LogLines, "log-lines", jobOptions.LogLines, "Number of log lines to print from failed Pods - 20 (default)")
+	waitForJobCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForEndpointsCmd
+	waitForCmd.AddCommand(waitForEndpointsCmd)
+	waitForEndpointsCmd.Flags().StringVar(&endpointsOptions.Namespace, "namespace", endpointsOptions.Namespace, "Namespace containing the resource (required)")
+	err = waitForEndpointsCmd.MarkFlagRequired("namespace")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForPodCmd.Flags().StringVar(&waitForCmdOptions.Label, "label", waitForCmdOptions.Label, "Label to select the resource in the form key=value (required)")
-	err = waitForPodCmd.MarkFlagRequired("label")
+	waitForEndpointsCmd.Flags().StringVar(&endpointsOptions.Service, "service", endpointsOptions.Service, "Service name (required)")
+	err = waitForEndpointsCmd.MarkFlagRequired("service")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForPodCmd.Flags().Int64Var(&waitForCmdOptions.Timeout, "timeout-seconds", 60, "Timeout seconds - 60 (default)")
-
-	// waitForStatefulSetCmd
-	waitForCmd.AddCommand(waitForStatefulSetCmd)
-	waitForStatefulSetCmd.Flags().StringVar(&waitForCmdOptions.Namespace, "namespace", waitForCmdOptions.Namespace, "Namespace containing the resource (required)")
-	err = waitForStatefulSetCmd.MarkFlagRequired("namespace")
+	waitForEndpointsCmd.Flags().StringVar(&endpointsOptions.PortName, "port-name", endpointsOptions.PortName, "Only count endpoints exposing this named port")
+	waitForEndpointsCmd.Flags().IntVar(&endpointsOptions.MinReady, "min-ready", endpointsOptions.MinReady, "Minimum number of ready addresses - 1 (default)")
+	waitForEndpointsCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForIngressCmd
+	waitForCmd.AddCommand(waitForIngressCmd)
+	addAddressFlags(waitForIngressCmd, ingressOptions)
+
+	// waitForLoadBalancerCmd
+	waitForCmd.AddCommand(waitForLoadBalancerCmd)
+	addAddressFlags(waitForLoadBalancerCmd, loadBalancerOptions)
+
+	// waitForCustomResourceDefinitionCmd
+	waitForCmd.AddCommand(waitForCustomResourceDefinitionCmd)
+	waitForCustomResourceDefinitionCmd.Flags().StringVar(&crdOptions.Name, "name", crdOptions.Name, "CustomResourceDefinition name, e.g. certificates.cert-manager.io (required)")
+	err = waitForCustomResourceDefinitionCmd.MarkFlagRequired("name")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForStatefulSetCmd.Flags().StringVar(&waitForCmdOptions.Label, "label", waitForCmdOptions.Label, "Label to select the resource in the form key=value (required)")
-	err = waitForStatefulSetCmd.MarkFlagRequired("label")
+	waitForCustomResourceDefinitionCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForAPICmd
+	waitForCmd.AddCommand(waitForAPICmd)
+	waitForAPICmd.Flags().StringVar(&apiOptions.GroupVersion, "group-version", apiOptions.GroupVersion, "API group version, e.g. external-secrets.io/v1beta1 (required)")
+	err = waitForAPICmd.MarkFlagRequired("group-version")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForStatefulSetCmd.Flags().Int64Var(&waitForCmdOptions.Timeout, "timeout-seconds", 60, "Timeout seconds - 60 (default)")
+	waitForAPICmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
 
 	// waitForClusterSecretStoreCmd
 	waitForCmd.AddCommand(waitForClusterSecretStoreCmd)
-	waitForClusterSecretStoreCmd.Flags().StringVar(&waitForCmdOptions.Name, "name", waitForCmdOptions.Name, "Resource name (required)")
-	err = waitForClusterSecretStoreCmd.MarkFlagRequired("name")
+	addNameFlags(waitForClusterSecretStoreCmd, clusterSecretStoreOptions, false)
+
+	// waitForSecretStoreCmd
+	waitForCmd.AddCommand(waitForSecretStoreCmd)
+	addNameFlags(waitForSecretStoreCmd, secretStoreOptions, true)
+
+	// waitForExternalSecretCmd
+	waitForCmd.AddCommand(waitForExternalSecretCmd)
+	waitForExternalSecretCmd.Flags().StringVar(&externalSecretOptions.Namespace, "namespace", externalSecretOptions.Namespace, "Namespace containing the resource (required)")
+	err = waitForExternalSecretCmd.MarkFlagRequired("namespace")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForExternalSecretCmd.Flags().StringVar(&externalSecretOptions.Name, "name", externalSecretOptions.Name, "Resource name (required)")
+	err = waitForExternalSecretCmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForExternalSecretCmd.Flags().BoolVar(&externalSecretOptions.CheckSecret, "check-secret", externalSecretOptions.CheckSecret, "Also wait for the target Secret to exist")
+	waitForExternalSecretCmd.Flags().StringSliceVar(&externalSecretOptions.SecretKeys, "secret-keys", externalSecretOptions.SecretKeys, "Comma separated keys the target Secret must contain, implies --check-secret")
+	waitForExternalSecretCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForArgoCDAppCmd
+	waitForCmd.AddCommand(waitForArgoCDAppCmd)
+	waitForArgoCDAppCmd.Flags().StringVar(&argoCDAppOptions.Namespace, "namespace", argoCDAppOptions.Namespace, "Namespace containing the Application (required)")
+	err = waitForArgoCDAppCmd.MarkFlagRequired("namespace")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForClusterSecretStoreCmd.Flags().Int64Var(&waitForCmdOptions.Timeout, "timeout-seconds", 60, "Timeout seconds - 60 (default)")
+	waitForArgoCDAppCmd.Flags().StringVar(&argoCDAppOptions.Name, "name", argoCDAppOptions.Name, "Resource name")
+	waitForArgoCDAppCmd.Flags().StringVar(&argoCDAppOptions.Label, "label", argoCDAppOptions.Label, "Label selector to match the resource, e.g. app.kubernetes.io/name=vault,tier in (web, api)")
+	waitForArgoCDAppCmd.Flags().StringVar(&argoCDAppOptions.FieldSelector, "field-selector", argoCDAppOptions.FieldSelector, "Field selector to match the resource, e.g. status.phase=Running")
+	waitForArgoCDAppCmd.Flags().StringSliceVar(&argoCDAppOptions.Health, "health", argoCDAppOptions.Health, "Comma separated health statuses to accept, e.g. Healthy,Degraded - Healthy (default)")
+	waitForArgoCDAppCmd.Flags().StringSliceVar(&argoCDAppOptions.Sync, "sync-status", argoCDAppOptions.Sync, "Comma separated sync statuses to accept, empty to ignore the sync status - Synced (default)")
+	waitForArgoCDAppCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
 
 	// waitForCertificateCmd
 	waitForCmd.AddCommand(waitForCertificateCmd)
-	waitForCertificateCmd.Flags().StringVar(&waitForCmdOptions.Namespace, "namespace", waitForCmdOptions.Namespace, "Namespace containing the resource (required)")
-	err = waitForCertificateCmd.MarkFlagRequired("namespace")
+	addNameFlags(waitForCertificateCmd, certificateOptions, true)
+
+	// waitForIssuerCmd
+	waitForCmd.AddCommand(waitForIssuerCmd)
+	addNameFlags(waitForIssuerCmd, issuerOptions, true)
+
+	// waitForClusterIssuerCmd
+	waitForCmd.AddCommand(waitForClusterIssuerCmd)
+	addNameFlags(waitForClusterIssuerCmd, clusterIssuerOptions, false)
+
+	// waitForHTTPCmd
+	waitForCmd.AddCommand(waitForHTTPCmd)
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.URL, "url", httpOptions.URL, "URL to probe (required)")
+	err = waitForHTTPCmd.MarkFlagRequired("url")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForHTTPCmd.Flags().IntVar(&httpOptions.ExpectStatus, "expect-status", httpOptions.ExpectStatus, "Expected HTTP status code - 200 (default)")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.ExpectBodyRegex, "expect-body-regex", httpOptions.ExpectBodyRegex, "Regex the response body must match")
+	waitForHTTPCmd.Flags().BoolVar(&httpOptions.InsecureSkipVerify, "insecure-skip-verify", httpOptions.InsecureSkipVerify, "Skip verification of the server certificate")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.TLSNamespace, "tls-namespace", httpOptions.TLSNamespace, "Namespace containing the CA bundle and client certificate")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.CASecret, "ca-secret", httpOptions.CASecret, "Secret containing a CA bundle to trust")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.CAConfigMap, "ca-configmap", httpOptions.CAConfigMap, "ConfigMap containing a CA bundle to trust")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.CAKey, "ca-key", httpOptions.CAKey, "Key of the CA bundle in the Secret or ConfigMap - ca.crt (default)")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.ClientCertSecret, "client-cert-secret", httpOptions.ClientCertSecret, "TLS Secret containing the client certificate and key")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.ClientCertFile, "client-cert-file", httpOptions.ClientCertFile, "Path to a PEM encoded client certificate")
+	waitForHTTPCmd.Flags().StringVar(&httpOptions.ClientKeyFile, "client-key-file", httpOptions.ClientKeyFile, "Path to a PEM encoded client key")
+	waitForHTTPCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForSecretCmd
+	waitForCmd.AddCommand(waitForSecretCmd)
+	addKeyFlags(waitForSecretCmd, secretOptions)
+
+	// waitForConfigMapCmd
+	waitForCmd.AddCommand(waitForConfigMapCmd)
+	addKeyFlags(waitForConfigMapCmd, configMapOptions)
+
+	// waitForTCPCmd
+	waitForCmd.AddCommand(waitForTCPCmd)
+	waitForTCPCmd.Flags().StringVar(&tcpOptions.Address, "address", tcpOptions.Address, "Address to connect to, e.g. postgres.db.svc.cluster.local:5432 (required)")
+	err = waitForTCPCmd.MarkFlagRequired("address")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForTCPCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForDNSCmd
+	waitForCmd.AddCommand(waitForDNSCmd)
+	waitForDNSCmd.Flags().StringVar(&dnsOptions.Name, "name", dnsOptions.Name, "DNS name to resolve (required)")
+	err = waitForDNSCmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForDNSCmd.Flags().StringVar(&dnsOptions.RecordType, "record-type", dnsOptions.RecordType, "Record type to resolve, one of A, AAAA, CNAME or TXT - A (default)")
+	waitForDNSCmd.Flags().StringSliceVar(&dnsOptions.Expect, "expect", dnsOptions.Expect, "Comma separated values the name must resolve to")
+	waitForDNSCmd.Flags().StringVar(&dnsOptions.Nameserver, "nameserver", dnsOptions.Nameserver, "Nameserver to query as host[:port] instead of the system resolver")
+	waitForDNSCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForResourceCmd
+	waitForCmd.AddCommand(waitForResourceCmd)
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.APIVersion, "api-version", resourceOptions.APIVersion, "API version of the resource, e.g. apps/v1 (required)")
+	err = waitForResourceCmd.MarkFlagRequired("api-version")
+	if err != nil {
+		log.Fatal(err)
+	}
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.Kind, "kind", resourceOptions.Kind, "Kind of the resource, e.g. Deployment (required)")
+	err = waitForResourceCmd.MarkFlagRequired("kind")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForCertificateCmd.Flags().StringVar(&waitForCmdOptions.Name, "name", waitForCmdOptions.Name, "Resource name (required)")
-	err = waitForCertificateCmd.MarkFlagRequired("name")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.Namespace, "namespace", resourceOptions.Namespace, "Namespace containing the resource, omit for cluster scoped resources")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.Name, "name", resourceOptions.Name, "Resource name")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.Selector, "selector", resourceOptions.Selector, "Label selector to match resources, e.g. app=foo,tier!=cache")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.FieldSelector, "field-selector", resourceOptions.FieldSelector, "Field selector to match resources, e.g. status.phase=Running")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.Condition, "condition", resourceOptions.Condition, "Status condition to wait for in the form Type or Type=Status, e.g. Ready=True")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.JSONPath, "jsonpath", resourceOptions.JSONPath, "JSONPath expression to evaluate, e.g. '{.status.phase}'")
+	waitForResourceCmd.Flags().StringVar(&resourceOptions.JSONPathValue, "jsonpath-value", resourceOptions.JSONPathValue, "Value the JSONPath expression must return")
+	addForFlag(waitForResourceCmd, &resourceOptions.For)
+	waitForResourceCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds - 60 (default)")
+
+	// waitForPlanCmd
+	waitForCmd.AddCommand(waitForPlanCmd)
+	waitForPlanCmd.Flags().StringVarP(&waitForCmdOptions.PlanFile, "file", "f", waitForCmdOptions.PlanFile, "Path to the plan file (required)")
+	err = waitForPlanCmd.MarkFlagRequired("file")
 	if err != nil {
 		log.Fatal(err)
 	}
-	waitForCertificateCmd.Flags().Int64Var(&waitForCmdOptions.Timeout, "timeout-seconds", 60, "Timeout seconds - 60 (default)")
+	waitForPlanCmd.Flags().Int64("timeout-seconds", 60, "Timeout seconds for targets that do not set their own - 60 (default)")
 }
@@ -0,0 +1,173 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	argoCDAPIVersion = "argoproj.io/v1alpha1"
+)
+
+// argoCDApplicationResource is the resource of Argo CD Applications
+var argoCDApplicationResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+
+// argoCDApplication contains the fields of an Argo CD Application used by the waiter
+type argoCDApplication struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Status            struct {
+		Sync struct {
+			Status   string `json:"status"`
+			Revision string `json:"revision"`
+		} `json:"sync"`
+		Health    argoCDHealthStatus `json:"health"`
+		Resources []struct {
+			Group     string              `json:"group"`
+			Kind      string              `json:"kind"`
+			Namespace string              `json:"namespace"`
+			Name      string              `json:"name"`
+			Status    string              `json:"status"`
+			Health    *argoCDHealthStatus `json:"health"`
+		} `json:"resources"`
+		OperationState *struct {
+			Phase   string `json:"phase"`
+			Message string `json:"message"`
+		} `json:"operationState"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// argoCDHealthStatus is the health of an Application or one of its resources
+type argoCDHealthStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// WaitForArgoCDApplicationReady waits for every Argo CD Application matching the selector
+// to reach one of the provided health statuses and, unless syncStatuses is empty, one of
+// the provided sync statuses
+func WaitForArgoCDApplicationReady(ctx context.Context, restConfig *rest.Config, namespace string, selector ObjectSelector, healthStatuses []string, syncStatuses []string) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error creating dynamic client: %s", err)
+	}
+	resource := dynamicClient.Resource(argoCDApplicationResource).Namespace(namespace)
+	lw := newListWatch[*unstructured.UnstructuredList](ctx, resource, selector.ListOptions())
+	log.Infof("waiting for Argo CD Application with %s - this could take up to %v", selector, TimeRemaining(ctx))
+
+	// The API group is not served until Argo CD has installed its CustomResourceDefinitions,
+	// the informer keeps retrying until it is
+	lastCondition := fmt.Sprintf("no Application was found or the %s API is not served", argoCDAPIVersion)
+	apps := make(map[string]*argoCDApplication)
+	var synced bool
+	ready := func() (bool, error) {
+		if !synced || len(apps) == 0 {
+			return false, nil
+		}
+		allReady := true
+		for _, app := range apps {
+			appReady, status := argoCDApplicationStatus(app, healthStatuses, syncStatuses)
+			if !appReady {
+				allReady = false
+				lastCondition = fmt.Sprintf("Application %s is %s", app.Name, status)
+				log.Info(lastCondition)
+			}
+		}
+		if allReady {
+			log.Infof("Argo CD Application validated")
+		}
+		return allReady, nil
+	}
+	err = watchUntil(ctx, lw, &unstructured.Unstructured{}, watchOptions{
+		kind: "Argo CD Application",
+		synced: func(store cache.Store) (bool, error) {
+			synced = true
+			return ready()
+		},
+	}, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false, nil
+		}
+		// The deleted Application may have been the only one that was not ready yet
+		if event.Type == watch.Deleted {
+			delete(apps, obj.GetName())
+			return ready()
+		}
+		app := &argoCDApplication{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, app); err != nil {
+			log.Errorf("error converting Argo CD Application data: %s", err)
+			return false, nil
+		}
+		apps[app.Name] = app
+		return ready()
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the Argo CD Application to be ready: %s", lastCondition)
+	}
+	return err
+}
+
+// argoCDApplicationStatus reports whether an Application has reached one of the target health
+// and sync statuses, along with its status and the health of any resource that is not healthy
+func argoCDApplicationStatus(app *argoCDApplication, healthStatuses []string, syncStatuses []string) (bool, string) {
+	status := fmt.Sprintf("%s and %s", app.Status.Sync.Status, app.Status.Health.Status)
+	if app.Status.Health.Message != "" {
+		status = fmt.Sprintf("%s: %s", status, app.Status.Health.Message)
+	}
+
+	ready := containsStatus(healthStatuses, app.Status.Health.Status) &&
+		(len(syncStatuses) == 0 || containsStatus(syncStatuses, app.Status.Sync.Status))
+	if ready {
+		return true, status
+	}
+
+	if operation := app.Status.OperationState; operation != nil && operation.Phase != "Succeeded" && operation.Phase != "Running" {
+		status = fmt.Sprintf("%s, last sync %s: %s", status, operation.Phase, operation.Message)
+	}
+	for _, condition := range app.Status.Conditions {
+		status = fmt.Sprintf("%s, %s: %s", status, condition.Type, condition.Message)
+	}
+
+	var unhealthy []string
+	for _, resource := range app.Status.Resources {
+		if resource.Health == nil || resource.Health.Status == "Healthy" {
+			continue
+		}
+		description := fmt.Sprintf("%s %s/%s is %s", resource.Kind, resource.Namespace, resource.Name, resource.Health.Status)
+		if resource.Health.Message != "" {
+			description = fmt.Sprintf("%s: %s", description, resource.Health.Message)
+		}
+		unhealthy = append(unhealthy, description)
+	}
+	if len(unhealthy) > 0 {
+		status = fmt.Sprintf("%s, resources not healthy: %s", status, strings.Join(unhealthy, "; "))
+	}
+
+	return false, status
+}
+
+// containsStatus reports whether status is one of statuses, ignoring case
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if strings.EqualFold(strings.TrimSpace(s), status) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import "testing"
+
+func testArgoCDApplication(syncStatus string, healthStatus string) *argoCDApplication {
+	app := &argoCDApplication{}
+	app.Status.Sync.Status = syncStatus
+	app.Status.Health.Status = healthStatus
+	return app
+}
+
+func TestArgoCDApplicationStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		app            *argoCDApplication
+		healthStatuses []string
+		syncStatuses   []string
+		want           bool
+	}{
+		{
+			name:           "healthy and synced",
+			app:            testArgoCDApplication("Synced", "Healthy"),
+			healthStatuses: []string{"Healthy"},
+			syncStatuses:   []string{"Synced"},
+			want:           true,
+		},
+		{
+			name:           "healthy but out of sync",
+			app:            testArgoCDApplication("OutOfSync", "Healthy"),
+			healthStatuses: []string{"Healthy"},
+			syncStatuses:   []string{"Synced"},
+			want:           false,
+		},
+		{
+			name:           "synced but progressing",
+			app:            testArgoCDApplication("Synced", "Progressing"),
+			healthStatuses: []string{"Healthy"},
+			syncStatuses:   []string{"Synced"},
+			want:           false,
+		},
+		{
+			name:           "one of several accepted health statuses",
+			app:            testArgoCDApplication("Synced", "Degraded"),
+			healthStatuses: []string{"Healthy", "Degraded"},
+			syncStatuses:   []string{"Synced"},
+			want:           true,
+		},
+		{
+			name:           "statuses match ignoring case and spaces",
+			app:            testArgoCDApplication("Synced", "Healthy"),
+			healthStatuses: []string{" healthy"},
+			syncStatuses:   []string{"synced "},
+			want:           true,
+		},
+		{
+			name:           "sync status ignored when none are accepted",
+			app:            testArgoCDApplication("OutOfSync", "Healthy"),
+			healthStatuses: []string{"Healthy"},
+			want:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := argoCDApplicationStatus(tt.app, tt.healthStatuses, tt.syncStatuses); got != tt.want {
+				t.Errorf("argoCDApplicationStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	app := testArgoCDApplication("OutOfSync", "Degraded")
+	app.Status.Health.Message = "1 Pod is crash looping"
+	_, description := argoCDApplicationStatus(app, []string{"Healthy"}, []string{"Synced"})
+	if want := "OutOfSync and Degraded: 1 Pod is crash looping"; description != want {
+		t.Errorf("argoCDApplicationStatus() = %s, want %s", description, want)
+	}
+}
@@ -2,48 +2,237 @@ package kubernetes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	certmanagermetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	cl "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 )
 
-// WaitForCertificateReady
-func WaitForCertificateReady(restConfig *rest.Config, namespace string, certificateName string, timeoutSeconds int64) error {
-	for i := int64(0); i <= timeoutSeconds; i++ {
-		log.Infof("waiting for Certificate %s", certificateName)
+// certificateResync is the interval at which a Certificate that is not ready is inspected
+// again, as the CertificateRequests, Orders and Challenges it depends on are not watched
+const certificateResync = 10 * time.Second
 
-		cmclient, err := cl.NewForConfig(restConfig)
-		if err != nil {
-			return err
-		}
+// WaitForCertificateReady waits for a Certificate to be ready, failing early when its
+// CertificateRequest, Order or Challenges have permanently failed
+func WaitForCertificateReady(ctx context.Context, restConfig *rest.Config, namespace string, certificateName string) error {
+	cmclient, err := cl.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	lw := newListWatch[*certmanagerv1.CertificateList](ctx, cmclient.CertmanagerV1().Certificates(namespace), nameListOptions(certificateName))
+	log.Infof("waiting for Certificate %s - this could take up to %v", certificateName, TimeRemaining(ctx))
 
-		cert, err := cmclient.CertmanagerV1().Certificates(namespace).Get(context.Background(), certificateName, metav1.GetOptions{})
-		if err != nil {
-			return err
+	lastCondition := fmt.Sprintf("Certificate %s was not found", certificateName)
+	err = watchUntil(ctx, lw, &certmanagerv1.Certificate{}, watchOptions{resync: certificateResync}, func(event watch.Event) (bool, error) {
+		cert, ok := event.Object.(*certmanagerv1.Certificate)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
 		}
 
-		var lastCondition string
 		for _, condition := range cert.Status.Conditions {
 			switch {
 			case condition.Type == certmanagerv1.CertificateConditionReady && condition.Status == certmanagermetav1.ConditionTrue:
 				log.Infof("Certificate validated")
-				return nil
+				return true, nil
 			default:
 				lastCondition = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
 			}
 		}
 
-		if i == timeoutSeconds {
-			return fmt.Errorf("timed out waiting for the Certificate to be ready: %s", lastCondition)
+		// Issuance is only inspected while the Certificate is not ready
+		failure, progress, err := certificateIssuanceStatus(ctx, cmclient, cert)
+		switch {
+		case err != nil && permanentError(err):
+			return false, err
+		case err != nil:
+			log.Warnf("error inspecting the issuance of Certificate %s, retrying: %s", certificateName, err)
+		case failure != "":
+			return false, fmt.Errorf("issuance of Certificate %s failed: %s", certificateName, failure)
+		case progress != "":
+			lastCondition = progress
+			log.Info(progress)
+		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the Certificate to be ready: %s", lastCondition)
+	}
+	return err
+}
+
+// certificateIssuanceStatus inspects the latest CertificateRequest of a Certificate and the
+// Order and Challenges created for it, returning the reason issuance permanently failed or,
+// while it is still in progress, a description of what issuance is waiting on
+// CertificateRequests and Orders carry the labels of their Certificate, so only those are
+// listed, and every object is matched through the owner references cert-manager sets
+// Only CertificateRequests of the revision being issued are inspected, as the failed request of
+// the previous revision is kept until cert-manager creates the request of the next one
+func certificateIssuanceStatus(ctx context.Context, cmclient cl.Interface, cert *certmanagerv1.Certificate) (string, string, error) {
+	listOptions := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(cert.Labels).String()}
+	requests, err := cmclient.CertmanagerV1().CertificateRequests(cert.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", "", err
+	}
+	nextRevision := 1
+	if cert.Status.Revision != nil {
+		nextRevision = *cert.Status.Revision + 1
+	}
+	var request *certmanagerv1.CertificateRequest
+	for i := range requests.Items {
+		cr := &requests.Items[i]
+		if cr.Annotations[certmanagerv1.CertificateNameKey] != cert.Name || !metav1.IsControlledBy(cr, cert) {
+			continue
+		}
+		if cr.Annotations[certmanagerv1.CertificateRequestRevisionAnnotationKey] != strconv.Itoa(nextRevision) {
+			continue
+		}
+		if request == nil || request.CreationTimestamp.Before(&cr.CreationTimestamp) {
+			request = cr
+		}
+	}
+	if request == nil {
+		return "", "", nil
+	}
+	if failure := certificateRequestFailure(request); failure != "" {
+		return fmt.Sprintf("CertificateRequest %s %s", request.Name, failure), "", nil
+	}
+
+	orders, err := cmclient.AcmeV1().Orders(cert.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", "", err
+	}
+	progress := fmt.Sprintf("CertificateRequest %s is pending", request.Name)
+	for i := range orders.Items {
+		order := &orders.Items[i]
+		if !metav1.IsControlledBy(order, request) || order.Status.State == cmacme.Valid {
+			continue
+		}
+
+		// Challenges are not labeled, they are only listed while an Order of the Certificate is not valid
+		challenges, err := cmclient.AcmeV1().Challenges(cert.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return "", "", err
+		}
+		for j := range challenges.Items {
+			challenge := &challenges.Items[j]
+			if !metav1.IsControlledBy(challenge, order) {
+				continue
+			}
+			if failure := challengeFailure(challenge); failure != "" {
+				return failure, "", nil
+			}
+			if challenge.Status.Reason != "" {
+				progress = fmt.Sprintf("%s challenge for %s is %s: %s", challenge.Spec.Type, challenge.Spec.DNSName, challenge.Status.State, challenge.Status.Reason)
+			}
+		}
+		if acmeStateFailed(order.Status.State) {
+			return fmt.Sprintf("Order %s is %s: %s", order.Name, order.Status.State, order.Status.Reason), "", nil
 		}
-		time.Sleep(time.Second * 1)
 	}
 
-	return nil
+	return "", progress, nil
+}
+
+// certificateRequestFailure returns why a CertificateRequest has permanently failed, if it has
+func certificateRequestFailure(cr *certmanagerv1.CertificateRequest) string {
+	for _, condition := range cr.Status.Conditions {
+		switch {
+		case condition.Type == certmanagerv1.CertificateRequestConditionReady && condition.Status == certmanagermetav1.ConditionFalse &&
+			(condition.Reason == certmanagerv1.CertificateRequestReasonFailed || condition.Reason == certmanagerv1.CertificateRequestReasonDenied):
+			return fmt.Sprintf("failed: %s", condition.Message)
+		case condition.Type == certmanagerv1.CertificateRequestConditionDenied && condition.Status == certmanagermetav1.ConditionTrue:
+			return fmt.Sprintf("was denied: %s: %s", condition.Reason, condition.Message)
+		case condition.Type == certmanagerv1.CertificateRequestConditionInvalidRequest && condition.Status == certmanagermetav1.ConditionTrue:
+			return fmt.Sprintf("is invalid: %s: %s", condition.Reason, condition.Message)
+		}
+	}
+	return ""
+}
+
+// challengeFailure returns why an ACME Challenge has permanently failed, if it has
+func challengeFailure(challenge *cmacme.Challenge) string {
+	if !acmeStateFailed(challenge.Status.State) {
+		return ""
+	}
+	return fmt.Sprintf("%s challenge for %s is %s: %s", challenge.Spec.Type, challenge.Spec.DNSName, challenge.Status.State, challenge.Status.Reason)
+}
+
+// acmeStateFailed reports whether an ACME Order or Challenge state is a final failed state
+func acmeStateFailed(state cmacme.State) bool {
+	return state == cmacme.Invalid || state == cmacme.Errored || state == cmacme.Expired
+}
+
+// WaitForIssuerReady waits for an Issuer, or a ClusterIssuer when namespace is empty, to be ready.
+// ACME issuers must also have registered their account with the ACME server
+func WaitForIssuerReady(ctx context.Context, restConfig *rest.Config, namespace string, issuerName string) error {
+	cmclient, err := cl.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	kind := "Issuer"
+	var lw cache.ListerWatcher
+	var objType runtime.Object
+	if namespace == "" {
+		kind = "ClusterIssuer"
+		lw = newListWatch[*certmanagerv1.ClusterIssuerList](ctx, cmclient.CertmanagerV1().ClusterIssuers(), nameListOptions(issuerName))
+		objType = &certmanagerv1.ClusterIssuer{}
+	} else {
+		lw = newListWatch[*certmanagerv1.IssuerList](ctx, cmclient.CertmanagerV1().Issuers(namespace), nameListOptions(issuerName))
+		objType = &certmanagerv1.Issuer{}
+	}
+	log.Infof("waiting for %s %s - this could take up to %v", kind, issuerName, TimeRemaining(ctx))
+
+	lastCondition := fmt.Sprintf("%s %s was not found", kind, issuerName)
+	err = watchUntil(ctx, lw, objType, watchOptions{}, func(event watch.Event) (bool, error) {
+		issuer, ok := event.Object.(certmanagerv1.GenericIssuer)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		var ready bool
+		ready, lastCondition = issuerReady(issuer)
+		if ready {
+			log.Infof("%s validated", kind)
+		}
+		return ready, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the %s to be ready: %s", kind, lastCondition)
+	}
+	return err
+}
+
+// issuerReady reports whether an Issuer or ClusterIssuer is ready, along with its last condition.
+// The Ready condition of a CA issuer covers the validity of its CA secret, ACME issuers must
+// also report the URI of their registered account
+func issuerReady(issuer certmanagerv1.GenericIssuer) (bool, string) {
+	var ready bool
+	var lastCondition string
+	for _, condition := range issuer.GetStatus().Conditions {
+		if condition.Type == certmanagerv1.IssuerConditionReady {
+			ready = condition.Status == certmanagermetav1.ConditionTrue
+			lastCondition = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+	if !ready {
+		return false, lastCondition
+	}
+	if issuer.GetSpec().ACME != nil {
+		if acme := issuer.GetStatus().ACME; acme == nil || acme.URI == "" {
+			return false, "the ACME account has not been registered"
+		}
+	}
+	return true, lastCondition
 }
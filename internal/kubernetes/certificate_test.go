@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagermetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCertificateRequestFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition certmanagerv1.CertificateRequestCondition
+		want      string
+	}{
+		{
+			name:      "pending",
+			condition: certmanagerv1.CertificateRequestCondition{Type: certmanagerv1.CertificateRequestConditionReady, Status: certmanagermetav1.ConditionFalse, Reason: certmanagerv1.CertificateRequestReasonPending, Message: "Waiting on certificate issuance"},
+			want:      "",
+		},
+		{
+			name:      "failed",
+			condition: certmanagerv1.CertificateRequestCondition{Type: certmanagerv1.CertificateRequestConditionReady, Status: certmanagermetav1.ConditionFalse, Reason: certmanagerv1.CertificateRequestReasonFailed, Message: "Failed to wait for order resource to become ready"},
+			want:      "failed: Failed to wait for order resource to become ready",
+		},
+		{
+			name:      "denied",
+			condition: certmanagerv1.CertificateRequestCondition{Type: certmanagerv1.CertificateRequestConditionDenied, Status: certmanagermetav1.ConditionTrue, Reason: "PolicyDenied", Message: "not allowed"},
+			want:      "was denied: PolicyDenied: not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &certmanagerv1.CertificateRequest{}
+			cr.Status.Conditions = []certmanagerv1.CertificateRequestCondition{tt.condition}
+			if got := certificateRequestFailure(cr); got != tt.want {
+				t.Errorf("certificateRequestFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallengeFailure(t *testing.T) {
+	challenge := &cmacme.Challenge{
+		Spec: cmacme.ChallengeSpec{DNSName: "kubefirst.dev", Type: cmacme.ACMEChallengeTypeDNS01},
+		Status: cmacme.ChallengeStatus{
+			State:  cmacme.Pending,
+			Reason: "Waiting for DNS-01 challenge propagation",
+		},
+	}
+	if got := challengeFailure(challenge); got != "" {
+		t.Errorf("challengeFailure() = %q, want no failure", got)
+	}
+
+	challenge.Status.State = cmacme.Invalid
+	challenge.Status.Reason = "DNS problem: NXDOMAIN looking up TXT for _acme-challenge.kubefirst.dev"
+	want := "DNS-01 challenge for kubefirst.dev is invalid: DNS problem: NXDOMAIN looking up TXT for _acme-challenge.kubefirst.dev"
+	if got := challengeFailure(challenge); got != want {
+		t.Errorf("challengeFailure() = %q, want %q", got, want)
+	}
+}
+
+func TestIssuerReady(t *testing.T) {
+	ready := certmanagerv1.IssuerCondition{Type: certmanagerv1.IssuerConditionReady, Status: certmanagermetav1.ConditionTrue, Reason: "ACMEAccountRegistered", Message: "The ACME account was registered with the ACME server"}
+
+	acmeIssuer := &certmanagerv1.ClusterIssuer{}
+	acmeIssuer.Spec.ACME = &cmacme.ACMEIssuer{Server: "https://acme-v02.api.letsencrypt.org/directory"}
+	acmeIssuer.Status.Conditions = []certmanagerv1.IssuerCondition{ready}
+	if got, _ := issuerReady(acmeIssuer); got {
+		t.Errorf("issuerReady() = true for an ACME issuer without a registered account")
+	}
+	acmeIssuer.Status.ACME = &cmacme.ACMEIssuerStatus{URI: "https://acme-v02.api.letsencrypt.org/acme/acct/1"}
+	if got, _ := issuerReady(acmeIssuer); !got {
+		t.Errorf("issuerReady() = false for a registered ACME issuer")
+	}
+
+	caIssuer := &certmanagerv1.Issuer{}
+	caIssuer.Spec.CA = &certmanagerv1.CAIssuer{SecretName: "ca"}
+	caIssuer.Status.Conditions = []certmanagerv1.IssuerCondition{{Type: certmanagerv1.IssuerConditionReady, Status: certmanagermetav1.ConditionFalse, Reason: "ErrGetKeyPair", Message: `Error getting keypair for CA issuer: secrets "ca" not found`}}
+	if got, condition := issuerReady(caIssuer); got || condition != `ErrGetKeyPair: Error getting keypair for CA issuer: secrets "ca" not found` {
+		t.Errorf("issuerReady() = %v, %s", got, condition)
+	}
+}
+
+func TestCertificateIssuanceStatus(t *testing.T) {
+	certificate := func(name string) *certmanagerv1.Certificate {
+		return &certmanagerv1.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name), Labels: map[string]string{"app": "web"}},
+		}
+	}
+	request := func(cert *certmanagerv1.Certificate, reason string) *certmanagerv1.CertificateRequest {
+		return &certmanagerv1.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            cert.Name + "-1",
+				Namespace:       cert.Namespace,
+				Labels:          cert.Labels,
+				Annotations:     map[string]string{certmanagerv1.CertificateNameKey: cert.Name, certmanagerv1.CertificateRequestRevisionAnnotationKey: "1"},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cert, certmanagerv1.SchemeGroupVersion.WithKind("Certificate"))},
+			},
+			Status: certmanagerv1.CertificateRequestStatus{Conditions: []certmanagerv1.CertificateRequestCondition{
+				{Type: certmanagerv1.CertificateRequestConditionReady, Status: certmanagermetav1.ConditionFalse, Reason: reason},
+			}},
+		}
+	}
+	web, api := certificate("web"), certificate("api")
+	cmclient := cmfake.NewSimpleClientset(request(web, certmanagerv1.CertificateRequestReasonPending), request(api, certmanagerv1.CertificateRequestReasonFailed))
+
+	// The failed request of another Certificate sharing the same labels is ignored
+	failure, progress, err := certificateIssuanceStatus(context.Background(), cmclient, web)
+	if err != nil || failure != "" || progress != "CertificateRequest web-1 is pending" {
+		t.Errorf("certificateIssuanceStatus() = %q, %q, %v", failure, progress, err)
+	}
+	failure, _, err = certificateIssuanceStatus(context.Background(), cmclient, api)
+	if err != nil || failure == "" {
+		t.Errorf("certificateIssuanceStatus() failure = %q, %v, want a failure", failure, err)
+	}
+
+	// The failed request of the previous revision is ignored while the Certificate is re-issued
+	revision := 1
+	api.Status.Revision = &revision
+	failure, progress, err = certificateIssuanceStatus(context.Background(), cmclient, api)
+	if err != nil || failure != "" || progress != "" {
+		t.Errorf("certificateIssuanceStatus() = %q, %q, %v, want no failure during re-issuance", failure, progress, err)
+	}
+
+	for _, action := range cmclient.Actions() {
+		list, ok := action.(k8stesting.ListAction)
+		if !ok {
+			continue
+		}
+		if list.GetResource().Resource == "challenges" {
+			t.Errorf("challenges were listed without a pending Order")
+		}
+		if selector := list.GetListRestrictions().Labels.String(); selector != "app=web" {
+			t.Errorf("%s were listed with selector %q, want app=web", list.GetResource().Resource, selector)
+		}
+	}
+}
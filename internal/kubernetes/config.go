@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -17,21 +18,37 @@ var fs afero.Fs = afero.NewOsFs()
 
 // CreateKubeConfig
 func CreateKubeConfig(inCluster string) (*rest.Config, kubernetes.Clientset, string) {
+	config, clientset, kubeconfig, err := loadKubeConfig(inCluster)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return config, *clientset, kubeconfig
+}
+
+// NewKubeConfig returns the rest config and clientset of the cluster, reporting a kubeconfig
+// that cannot be loaded as an error rather than exiting
+func NewKubeConfig(inCluster string) (*rest.Config, *kubernetes.Clientset, error) {
+	config, clientset, _, err := loadKubeConfig(inCluster)
+	return config, clientset, err
+}
+
+// loadKubeConfig builds the rest config and clientset, and returns the kubeconfig path used
+func loadKubeConfig(inCluster string) (*rest.Config, *kubernetes.Clientset, string, error) {
 	// inCluster is either true or false
 	// If it's true, we pull Kubernetes API authentication from Pod SA
 	// If it's false, we use local machine settings
 	if inCluster == "true" {
 		config, err := rest.InClusterConfig()
 		if err != nil {
-			panic(err.Error())
+			return nil, nil, "", fmt.Errorf("error loading in-cluster config: %s", err)
 		}
 
 		clientset, err := kubernetes.NewForConfig(config)
 		if err != nil {
-			panic(err.Error())
+			return nil, nil, "", fmt.Errorf("error creating clientset: %s", err)
 		}
 
-		return config, *clientset, "in-cluster"
+		return config, clientset, "in-cluster", nil
 	}
 
 	// Set path to kubeconfig
@@ -49,16 +66,16 @@ func CreateKubeConfig(inCluster string) (*rest.Config, kubernetes.Clientset, str
 	// Build configuration instance from the provided config file
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
-		log.Fatalf("unable to locate kubeconfig file - checked path: %s", kubeconfig)
+		return nil, nil, "", fmt.Errorf("unable to locate kubeconfig file - checked path: %s", kubeconfig)
 	}
 
 	// Create clientset, which is used to run operations against the API
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		panic(err)
+		return nil, nil, "", fmt.Errorf("error creating clientset: %s", err)
 	}
 
-	return config, *clientset, kubeconfig
+	return config, clientset, kubeconfig, nil
 }
 
 // ReturnKubeConfigPath generates the path in the filesystem to kubeconfig
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// WaitForCustomResourceDefinitionEstablished waits for a CustomResourceDefinition to have
+// its names accepted and to be established, so that custom resources can be created
+func WaitForCustomResourceDefinitionEstablished(ctx context.Context, restConfig *rest.Config, name string) error {
+	client, err := apiextensionsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error creating apiextensions client: %s", err)
+	}
+
+	lw := newListWatch[*apiextensionsv1.CustomResourceDefinitionList](ctx, client.ApiextensionsV1().CustomResourceDefinitions(), nameListOptions(name))
+	log.Infof("waiting for CustomResourceDefinition %s to be established - this could take up to %v", name, TimeRemaining(ctx))
+
+	lastCondition := "the CustomResourceDefinition does not exist"
+	err = watchUntil(ctx, lw, &apiextensionsv1.CustomResourceDefinition{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		crd, ok := event.Object.(*apiextensionsv1.CustomResourceDefinition)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			lastCondition = "the CustomResourceDefinition was deleted"
+			return false, nil
+		}
+
+		var established, namesAccepted bool
+		for _, condition := range crd.Status.Conditions {
+			switch condition.Type {
+			case apiextensionsv1.Established:
+				established = condition.Status == apiextensionsv1.ConditionTrue
+			case apiextensionsv1.NamesAccepted:
+				namesAccepted = condition.Status == apiextensionsv1.ConditionTrue
+				if condition.Status == apiextensionsv1.ConditionFalse {
+					return false, fmt.Errorf("the names of CustomResourceDefinition %s were not accepted: %s: %s", name, condition.Reason, condition.Message)
+				}
+			}
+			if condition.Status != apiextensionsv1.ConditionTrue {
+				lastCondition = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			}
+		}
+		if established && namesAccepted {
+			log.Infof("CustomResourceDefinition %s is established", name)
+			return true, nil
+		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the CustomResourceDefinition was not established within the timeout period")
+		return fmt.Errorf("timed out waiting for the CustomResourceDefinition to be established: %s", lastCondition)
+	}
+	return err
+}
+
+// WaitForAPIGroupVersion waits for API discovery to serve the resources of a group version,
+// e.g. external-secrets.io/v1beta1
+func WaitForAPIGroupVersion(ctx context.Context, restConfig *rest.Config, groupVersion string) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error creating discovery client: %s", err)
+	}
+	log.Infof("waiting for API %s - this could take up to %v", groupVersion, TimeRemaining(ctx))
+
+	var lastErr error
+	err = pollUntil(ctx, func(ctx context.Context) (bool, error) {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+		switch {
+		// The group version is not served until whatever provides it has been installed
+		case apierrors.IsNotFound(err):
+			lastErr = err
+			return false, nil
+		case err != nil:
+			lastErr = err
+			return false, err
+		case len(resources.APIResources) == 0:
+			lastErr = fmt.Errorf("no resources are served by %s", groupVersion)
+			return false, nil
+		}
+		log.Infof("API %s is available", groupVersion)
+		return true, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for API %s to be available: %s", groupVersion, lastErr)
+	}
+	return err
+}
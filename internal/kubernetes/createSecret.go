@@ -39,13 +39,13 @@ func CreateK8sSecret(clientset *kubernetes.Clientset, o *CreateK8sSecretCmdOptio
 
 	_, err := clientset.CoreV1().Secrets(secret.ObjectMeta.Namespace).Get(context.TODO(), secret.ObjectMeta.Name, metav1.GetOptions{})
 	if err == nil {
-		fmt.Println("kubernetes secret %s/%s already created - skipping", secret.Namespace, secret.Name)
+		fmt.Printf("kubernetes secret %s/%s already created - skipping\n", secret.Namespace, secret.Name)
 	} else if strings.Contains(err.Error(), "not found") {
 		_, err = clientset.CoreV1().Secrets(secret.ObjectMeta.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
 		if err != nil {
-			fmt.Println("error creating kubernetes secret %s/%s: %s", secret.Namespace, secret.Name, err)
+			fmt.Printf("error creating kubernetes secret %s/%s: %s\n", secret.Namespace, secret.Name, err)
 		}
-		fmt.Println("created kubernetes secret: %s/%s", secret.Namespace, secret.Name)
+		fmt.Printf("created kubernetes secret: %s/%s\n", secret.Namespace, secret.Name)
 	}
 	return nil
 }
@@ -2,57 +2,175 @@ package kubernetes
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	v1beta1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1beta1"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const (
 	externalSecretsAPIVersion = "external-secrets.io/v1beta1"
 )
 
-// WaitForClusterSecretStoreReady
-func WaitForClusterSecretStoreReady(clientset *kubernetes.Clientset, storeName string, timeoutSeconds int64) error {
-	for i := int64(0); i <= timeoutSeconds; i++ {
-		log.Infof("waiting for ClusterSecretStore %s", storeName)
+// WaitForClusterSecretStoreReady waits for an External Secrets Operator ClusterSecretStore to be ready
+func WaitForClusterSecretStoreReady(ctx context.Context, restConfig *rest.Config, storeName string) error {
+	return waitForSecretStoreReady(ctx, restConfig, "ClusterSecretStore", "clustersecretstores", "", storeName)
+}
 
-		// Call the API to return matched ClusterSecretStore objects
-		data, err := clientset.CoreV1().RESTClient().Get().
-			AbsPath(fmt.Sprintf("/apis/%s", externalSecretsAPIVersion)).
-			Resource("clustersecretstores").
-			Name(storeName).
-			DoRaw(context.Background())
-		if err != nil {
-			return fmt.Errorf("error retrieving ClusterSecretStore: %s", err)
-		}
+// WaitForSecretStoreReady waits for a namespaced External Secrets Operator SecretStore to be ready
+func WaitForSecretStoreReady(ctx context.Context, restConfig *rest.Config, namespace string, storeName string) error {
+	return waitForSecretStoreReady(ctx, restConfig, "SecretStore", "secretstores", namespace, storeName)
+}
+
+// externalSecretsResource returns a dynamic client for an External Secrets Operator resource
+func externalSecretsResource(restConfig *rest.Config, resource string, namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %s", err)
+	}
+	gvr := v1beta1.SchemeGroupVersion.WithResource(resource)
+	if namespace == "" {
+		return dynamicClient.Resource(gvr), nil
+	}
+	return dynamicClient.Resource(gvr).Namespace(namespace), nil
+}
+
+// waitForSecretStoreReady waits for the Ready condition of a SecretStore or ClusterSecretStore,
+// which share the same status
+func waitForSecretStoreReady(ctx context.Context, restConfig *rest.Config, kind string, resource string, namespace string, storeName string) error {
+	client, err := externalSecretsResource(restConfig, resource, namespace)
+	if err != nil {
+		return err
+	}
+	lw := newListWatch[*unstructured.UnstructuredList](ctx, client, nameListOptions(storeName))
+	log.Infof("waiting for %s %s - this could take up to %v", kind, storeName, TimeRemaining(ctx))
 
-		// Unmarshal JSON API response to ClusterSecretStore object
-		var resp *v1beta1.ClusterSecretStore
-		if err := json.Unmarshal(data, &resp); err != nil {
-			log.Errorf("error converting ClusterSecretStore data: %s", err)
+	// The API group is not served until External Secrets Operator has installed its
+	// CustomResourceDefinitions, the informer keeps retrying until it is
+	lastCondition := fmt.Sprintf("%s %s was not found or the %s API is not served", kind, storeName, externalSecretsAPIVersion)
+	err = watchUntil(ctx, lw, &unstructured.Unstructured{}, watchOptions{kind: kind}, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		store := &v1beta1.SecretStore{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, store); err != nil {
+			log.Errorf("error converting %s data: %s", kind, err)
+			return false, nil
 		}
 
-		var lastCondition string
-		for _, condition := range resp.Status.Conditions {
+		for _, condition := range store.Status.Conditions {
 			switch {
 			case condition.Type == v1beta1.SecretStoreReady && condition.Status == v1.ConditionTrue:
-				log.Infof("ClusterSecretStore validated")
-				return nil
+				log.Infof("%s validated", kind)
+				return true, nil
 			default:
 				lastCondition = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
 			}
 		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the %s to be ready: %s", kind, lastCondition)
+	}
+	return err
+}
+
+// WaitForExternalSecretSynced waits for an ExternalSecret to have synced its target Secret.
+// When checkSecret is set, it also waits for the target Secret to exist and to contain secretKeys
+func WaitForExternalSecretSynced(ctx context.Context, restConfig *rest.Config, namespace string, name string, checkSecret bool, secretKeys []string) error {
+	client, err := externalSecretsResource(restConfig, "externalsecrets", namespace)
+	if err != nil {
+		return err
+	}
+	lw := newListWatch[*unstructured.UnstructuredList](ctx, client, nameListOptions(name))
+	log.Infof("waiting for ExternalSecret %s - this could take up to %v", name, TimeRemaining(ctx))
 
-		if i == timeoutSeconds {
-			return fmt.Errorf("timed out waiting for the ClusterSecretStore to be ready: %s", lastCondition)
+	lastCondition := fmt.Sprintf("ExternalSecret %s was not found or the %s API is not served", name, externalSecretsAPIVersion)
+	var secretName string
+	err = watchUntil(ctx, lw, &unstructured.Unstructured{}, watchOptions{kind: "ExternalSecret"}, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
 		}
-		time.Sleep(time.Second * 1)
+		externalSecret := &v1beta1.ExternalSecret{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, externalSecret); err != nil {
+			log.Errorf("error converting ExternalSecret data: %s", err)
+			return false, nil
+		}
+
+		for _, condition := range externalSecret.Status.Conditions {
+			switch {
+			case condition.Type == v1beta1.ExternalSecretReady && condition.Status == v1.ConditionTrue:
+				secretName = externalSecret.Spec.Target.Name
+				if secretName == "" {
+					secretName = externalSecret.Name
+				}
+				return true, nil
+			default:
+				lastCondition = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			}
+		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the ExternalSecret to be synced: %s", lastCondition)
+	}
+	if err != nil {
+		return err
 	}
 
+	if checkSecret {
+		if err := waitForSyncedSecret(ctx, restConfig, namespace, secretName, secretKeys); err != nil {
+			return err
+		}
+	}
+	log.Infof("ExternalSecret validated")
 	return nil
 }
+
+// waitForSyncedSecret waits for the target Secret of an ExternalSecret to exist and to contain secretKeys
+func waitForSyncedSecret(ctx context.Context, restConfig *rest.Config, namespace string, secretName string, secretKeys []string) error {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error creating kubernetes client: %s", err)
+	}
+	lw := newListWatch[*v1.SecretList](ctx, clientset.CoreV1().Secrets(namespace), nameListOptions(secretName))
+
+	lastCondition := fmt.Sprintf("Secret %s was not found", secretName)
+	err = watchUntil(ctx, lw, &v1.Secret{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		secret, ok := event.Object.(*v1.Secret)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		if missing := missingSecretKeys(secret, secretKeys); len(missing) > 0 {
+			lastCondition = fmt.Sprintf("Secret %s is missing keys %s", secretName, strings.Join(missing, ", "))
+			return false, nil
+		}
+		return true, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for the ExternalSecret to be synced: %s", lastCondition)
+	}
+	return err
+}
+
+// missingSecretKeys returns the keys that are not present in the data of a Secret
+func missingSecretKeys(secret *v1.Secret, keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
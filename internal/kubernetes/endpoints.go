@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForServiceEndpoints waits for the EndpointSlices of a Service to contain at least
+// minReady ready addresses, only counting addresses that expose portName when it is set
+func WaitForServiceEndpoints(ctx context.Context, clientset *kubernetes.Clientset, namespace string, serviceName string, portName string, minReady int) error {
+	// The Service is often created by the same sync as its Pods, so wait for it to exist too
+	var service *v1.Service
+	err := pollUntil(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		service, err = ReadService(ctx, clientset, namespace, serviceName)
+		if apierrors.IsNotFound(err) {
+			log.Infof("waiting for Service %s to be created", serviceName)
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("the Service %s was not created within the timeout period", serviceName)
+	}
+	if err != nil {
+		return err
+	}
+	if portName != "" {
+		found := false
+		for _, port := range service.Spec.Ports {
+			if port.Name == portName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("service %s does not define a port named %s", serviceName, portName)
+		}
+	}
+
+	lw := newListWatch[*discoveryv1.EndpointSliceList](ctx, clientset.DiscoveryV1().EndpointSlices(namespace), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, serviceName),
+	})
+	log.Infof("waiting for Service %s to have %v ready endpoints - this could take up to %v", serviceName, minReady, TimeRemaining(ctx))
+
+	slices := make(map[string]*discoveryv1.EndpointSlice)
+	var ready int
+	err = watchUntil(ctx, lw, &discoveryv1.EndpointSlice{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		slice, ok := event.Object.(*discoveryv1.EndpointSlice)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			delete(slices, slice.Name)
+		} else {
+			slices[slice.Name] = slice
+		}
+
+		ready = countReadyEndpoints(slices, portName)
+		if ready >= minReady {
+			log.Infof("Service %s has %v ready endpoints", serviceName, ready)
+			return true, nil
+		}
+		log.Infof("Service %s has %v of %v ready endpoints", serviceName, ready, minReady)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the Service endpoints were not ready within the timeout period")
+		if len(slices) == 0 && len(service.Spec.Selector) > 0 {
+			return fmt.Errorf("no endpoints were found for Service %s, check that its selector %s matches the labels of running Pods",
+				serviceName, labels.SelectorFromSet(service.Spec.Selector))
+		}
+		return fmt.Errorf("service %s only has %v of %v ready endpoints", serviceName, ready, minReady)
+	}
+	if err != nil {
+		return fmt.Errorf("error waiting for EndpointSlices of Service %s: %s", serviceName, err)
+	}
+	return nil
+}
+
+// countReadyEndpoints returns the number of unique ready addresses in a set of EndpointSlices,
+// only counting slices that expose portName when it is set
+func countReadyEndpoints(slices map[string]*discoveryv1.EndpointSlice, portName string) int {
+	addresses := make(map[string]bool)
+	for _, slice := range slices {
+		if portName != "" && !endpointSliceHasPort(slice, portName) {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition is interpreted as ready
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				addresses[address] = true
+			}
+		}
+	}
+	return len(addresses)
+}
+
+// endpointSliceHasPort reports whether an EndpointSlice exposes a port with the provided name
+func endpointSliceHasPort(slice *discoveryv1.EndpointSlice, portName string) bool {
+	for _, port := range slice.Ports {
+		if port.Name != nil && *port.Name == portName {
+			return true
+		}
+	}
+	return false
+}
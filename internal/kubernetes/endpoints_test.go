@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestCountReadyEndpoints(t *testing.T) {
+	slices := map[string]*discoveryv1.EndpointSlice{
+		"web-http": {
+			Ports: []discoveryv1.EndpointPort{{Name: stringPtr("http")}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(true)}},
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(false)}},
+				{Addresses: []string{"10.0.0.3"}},
+			},
+		},
+		"web-metrics": {
+			Ports: []discoveryv1.EndpointPort{{Name: stringPtr("metrics")}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(true)}},
+				{Addresses: []string{"10.0.0.4"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(true)}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		portName string
+		want     int
+	}{
+		{name: "any port counts unique addresses", want: 3},
+		{name: "named port", portName: "http", want: 2},
+		{name: "other named port", portName: "metrics", want: 2},
+		{name: "unknown port", portName: "grpc", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countReadyEndpoints(slices, tt.portName); got != tt.want {
+				t.Errorf("countReadyEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
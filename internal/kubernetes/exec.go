@@ -6,25 +6,27 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"time"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	terminal "golang.org/x/term"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
 // ReadService reads a Kubernetes Service object
-func ReadService(clientset *kubernetes.Clientset, namespace string, serviceName string) (*v1.Service, error) {
-	service, err := clientset.CoreV1().Services(namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
+func ReadService(ctx context.Context, clientset *kubernetes.Clientset, namespace string, serviceName string) (*v1.Service, error) {
+	service, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
 	if err != nil {
-		log.Errorf("error getting Service: %s", err)
-		return &v1.Service{}, nil
+		return nil, fmt.Errorf("error getting Service %s: %w", serviceName, err)
 	}
 
 	return service, nil
@@ -91,320 +93,494 @@ func podExec(clientset *kubernetes.Clientset, config *rest.Config, ps *PodSessio
 	return nil
 }
 
-// ReturnDeploymentObject returns a matching appsv1.Deployment object based on the filters
-func ReturnDeploymentObject(clientset *kubernetes.Clientset, matchLabel string, matchLabelValue string, namespace string, timeoutSeconds int64) (*appsv1.Deployment, error) {
-	// Filter
-	deploymentListOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", matchLabel, matchLabelValue),
+// ReturnDeploymentObject returns a matching appsv1.Deployment object based on the selector
+func ReturnDeploymentObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*appsv1.Deployment, error) {
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments(namespace), selector.ListOptions())
+	obj, err := waitForObject(ctx, lw, &appsv1.Deployment{}, "Deployment", selector)
+	if err != nil {
+		return nil, err
 	}
+	return obj.(*appsv1.Deployment), nil
+}
 
-	// Create watch operation
-	objWatch, err := clientset.
-		AppsV1().
-		Deployments(namespace).
-		Watch(context.Background(), deploymentListOptions)
+// ReturnPodObject returns a matching v1.Pod object based on the selector
+func ReturnPodObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*v1.Pod, error) {
+	lw := newListWatch[*v1.PodList](ctx, clientset.CoreV1().Pods(namespace), selector.ListOptions())
+	// Readiness is determined by WaitForPodReady, here the Pod only has to exist
+	obj, err := waitForObject(ctx, lw, &v1.Pod{}, "Pod", selector)
 	if err != nil {
-		log.Fatalf("error when attempting to search for Deployment: %s", err)
-	}
-	log.Infof("waiting for %s Deployment to be created", matchLabelValue)
-
-	objChan := objWatch.ResultChan()
-	for {
-		select {
-		case event, ok := <-objChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for %s Deployment to be created: %s", matchLabelValue, err)
-			}
-			if event.
-				Object.(*appsv1.Deployment).Status.Replicas > 0 {
-				spec, err := clientset.AppsV1().Deployments(namespace).List(context.Background(), deploymentListOptions)
-				if err != nil {
-					log.Fatalf("error when searching for Deployment: %s", err)
-					return nil, err
-				}
-				return &spec.Items[0], nil
-			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the Deployment was not created within the timeout period")
-			return nil, fmt.Errorf("the Deployment was not created within the timeout period")
-		}
+		return nil, err
 	}
+	return obj.(*v1.Pod), nil
 }
 
-// ReturnPodObject returns a matching v1.Pod object based on the filters
-func ReturnPodObject(clientset *kubernetes.Clientset, matchLabel string, matchLabelValue string, namespace string, timeoutSeconds int64) (*v1.Pod, error) {
-	// Filter
-	podListOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", matchLabel, matchLabelValue),
+// ReturnStatefulSetObject returns a matching appsv1.StatefulSet object based on the selector
+func ReturnStatefulSetObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*appsv1.StatefulSet, error) {
+	lw := newListWatch[*appsv1.StatefulSetList](ctx, clientset.AppsV1().StatefulSets(namespace), selector.ListOptions())
+	obj, err := waitForObject(ctx, lw, &appsv1.StatefulSet{}, "StatefulSet", selector)
+	if err != nil {
+		return nil, err
 	}
+	return obj.(*appsv1.StatefulSet), nil
+}
 
-	// Create watch operation
-	objWatch, err := clientset.
-		CoreV1().
-		Pods(namespace).
-		Watch(context.Background(), podListOptions)
+// ReturnDaemonSetObject returns a matching appsv1.DaemonSet object based on the selector
+func ReturnDaemonSetObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*appsv1.DaemonSet, error) {
+	lw := newListWatch[*appsv1.DaemonSetList](ctx, clientset.AppsV1().DaemonSets(namespace), selector.ListOptions())
+	obj, err := waitForObject(ctx, lw, &appsv1.DaemonSet{}, "DaemonSet", selector)
 	if err != nil {
-		log.Fatalf("error when attempting to search for Pod: %s", err)
-	}
-	log.Infof("waiting for %s Pod to be created", matchLabelValue)
-
-	objChan := objWatch.ResultChan()
-	for {
-		select {
-		case event, ok := <-objChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for %s Pod to be created: %s", matchLabelValue, err)
-			}
-			if event.
-				Object.(*v1.Pod).Status.Phase == "Pending" {
-				spec, err := clientset.CoreV1().Pods(namespace).List(context.Background(), podListOptions)
-				if err != nil {
-					log.Fatalf("error when searching for Pod: %s", err)
-					return nil, err
+		return nil, err
+	}
+	return obj.(*appsv1.DaemonSet), nil
+}
+
+// ReturnPersistentVolumeClaimObject returns a matching v1.PersistentVolumeClaim object based on the selector
+func ReturnPersistentVolumeClaimObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*v1.PersistentVolumeClaim, error) {
+	lw := newListWatch[*v1.PersistentVolumeClaimList](ctx, clientset.CoreV1().PersistentVolumeClaims(namespace), selector.ListOptions())
+	obj, err := waitForObject(ctx, lw, &v1.PersistentVolumeClaim{}, "PersistentVolumeClaim", selector)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.PersistentVolumeClaim), nil
+}
+
+// waitForObject waits for an object returned by lw to exist
+// When several objects already match, the first one by name is returned
+func waitForObject(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, kind string, selector ObjectSelector) (runtime.Object, error) {
+	log.Infof("waiting for %s with %s to be created", kind, selector)
+
+	var result runtime.Object
+	var synced bool
+	err := watchUntil(ctx, lw, objType, watchOptions{
+		synced: func(store cache.Store) (bool, error) {
+			synced = true
+			var candidates []runtime.Object
+			for _, item := range store.List() {
+				if obj, ok := item.(runtime.Object); ok {
+					candidates = append(candidates, obj)
 				}
-				return &spec.Items[0], nil
 			}
-			if event.
-				Object.(*v1.Pod).Status.Phase == "Running" {
-				spec, err := clientset.CoreV1().Pods(namespace).List(context.Background(), podListOptions)
-				if err != nil {
-					log.Fatalf("error when searching for Pod: %s", err)
-					return nil, err
-				}
-				return &spec.Items[0], nil
+			if len(candidates) == 0 {
+				return false, nil
 			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the Pod was not created within the timeout period")
-			return nil, fmt.Errorf("the Pod was not created within the timeout period")
+			sortObjectsByName(candidates)
+			result = candidates[0]
+			if len(candidates) > 1 {
+				log.Warnf("%v %s objects match %s, only %s will be waited on - use --all or --min-count to wait for all of them", len(candidates), kind, selector, objectName(result))
+			}
+			return true, nil
+		},
+	}, func(event watch.Event) (bool, error) {
+		// Objects from the initial list are considered together once it has synced
+		if !synced || event.Type == watch.Deleted {
+			return false, nil
 		}
+		result = event.Object
+		return true, nil
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		log.Errorf("the %s was not created within the timeout period", kind)
+		return nil, fmt.Errorf("the %s was not created within the timeout period", kind)
+	case err != nil:
+		return nil, fmt.Errorf("error waiting for %s with %s to be created: %s", kind, selector, err)
 	}
+	return result, nil
 }
 
-// ReturnStatefulSetObject returns a matching appsv1.StatefulSet object based on the filters
-func ReturnStatefulSetObject(clientset *kubernetes.Clientset, matchLabel string, matchLabelValue string, namespace string, timeoutSeconds int64) (*appsv1.StatefulSet, error) {
-	// Filter
-	statefulSetListOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", matchLabel, matchLabelValue),
+// WaitForDeploymentReady waits for a target Deployment to finish rolling out
+// This follows the same semantics as kubectl rollout status and fails immediately
+// if the Deployment exceeds its progress deadline
+func WaitForDeploymentReady(ctx context.Context, clientset *kubernetes.Clientset, deployment *appsv1.Deployment) (bool, error) {
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments(deployment.Namespace), nameListOptions(deployment.Name))
+	log.Infof("waiting for %s Deployment to be ready - this could take up to %v", deployment.Name, TimeRemaining(ctx))
+
+	lastStatus := fmt.Sprintf("Deployment %s does not exist", deployment.Name)
+	err := watchUntil(ctx, lw, &appsv1.Deployment{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*appsv1.Deployment)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			lastStatus = fmt.Sprintf("Deployment %s was deleted", deployment.Name)
+			return false, nil
+		}
+		done, status, err := deploymentRolloutStatus(current)
+		if err != nil {
+			log.Errorf("Deployment %s failed to roll out: %s", deployment.Name, err)
+			return false, err
+		}
+		if done {
+			log.Infof("all Pods in Deployment %s are ready", deployment.Name)
+			return true, nil
+		}
+		lastStatus = status
+		log.Infof("Deployment %s: %s", deployment.Name, status)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the Deployment was not ready within the timeout period")
+		return false, fmt.Errorf("the Deployment was not ready within the timeout period: %s", lastStatus)
 	}
+	return err == nil, err
+}
 
-	// Create watch operation
-	objWatch, err := clientset.
-		AppsV1().
-		StatefulSets(namespace).
-		Watch(context.Background(), statefulSetListOptions)
-	if err != nil {
-		log.Fatalf("error when attempting to search for StatefulSet: %s", err)
-	}
-	log.Infof("waiting for %s StatefulSet to be created using label %s=%s", matchLabelValue, matchLabel, matchLabelValue)
-
-	objChan := objWatch.ResultChan()
-	for {
-		select {
-		case event, ok := <-objChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for %s StatefulSet to be created: %s", matchLabelValue, err)
-			}
-			if event.
-				Object.(*appsv1.StatefulSet).Status.Replicas > 0 {
-				spec, err := clientset.AppsV1().StatefulSets(namespace).List(context.Background(), statefulSetListOptions)
-				if err != nil {
-					log.Fatalf("error when searching for StatefulSet: %s", err)
-					return nil, err
-				}
-				return &spec.Items[0], nil
-			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the StatefulSet was not created within the timeout period")
-			return nil, fmt.Errorf("the StatefulSet was not created within the timeout period")
+// deploymentRolloutStatus reports whether a Deployment has completed its rollout
+// along with a description of its progress, matching kubectl rollout status
+// An error is returned when the Deployment has exceeded its progress deadline
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (bool, string, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, "waiting for the Deployment spec update to be observed", nil
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return false, "", fmt.Errorf("deployment %s exceeded its progress deadline: %s", deployment.Name, condition.Message)
 		}
 	}
+
+	status := deployment.Status
+	if deployment.Spec.Replicas != nil && status.UpdatedReplicas < *deployment.Spec.Replicas {
+		return false, fmt.Sprintf("%v out of %v new replicas have been updated", status.UpdatedReplicas, *deployment.Spec.Replicas), nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return false, fmt.Sprintf("%v old replicas are pending termination", status.Replicas-status.UpdatedReplicas), nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return false, fmt.Sprintf("%v of %v updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	}
+	return true, fmt.Sprintf("%v of %v updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
 }
 
-// WaitForDeploymentReady waits for a target Deployment to become ready
-func WaitForDeploymentReady(clientset *kubernetes.Clientset, deployment *appsv1.Deployment, timeoutSeconds int64) (bool, error) {
+// WaitForPodReady waits for a target Pod to become ready
+// The Pod is ready once its Ready condition is true and all of its containers are ready
+// It fails early when a container is failing in a way that will not recover on its own
+func WaitForPodReady(ctx context.Context, clientset *kubernetes.Clientset, pod *v1.Pod) (bool, error) {
+	lw := newListWatch[*v1.PodList](ctx, clientset.CoreV1().Pods(pod.Namespace), nameListOptions(pod.Name))
+	log.Infof("waiting for %s Pod to be ready - this could take up to %v", pod.Name, TimeRemaining(ctx))
 
-	// Format list for metav1.ListOptions for watch
-	configuredReplicas := deployment.Status.Replicas
-	watchOptions := metav1.ListOptions{
-		FieldSelector: fmt.Sprintf(
-			"metadata.name=%s", deployment.Name),
+	// Listen until the Pod is ready
+	lastStatus := fmt.Sprintf("Pod %s does not exist", pod.Name)
+	err := watchUntil(ctx, lw, &v1.Pod{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*v1.Pod)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			lastStatus = fmt.Sprintf("Pod %s was deleted", pod.Name)
+			return false, nil
+		}
+		if err := podFailure(current); err != nil {
+			log.Errorf("Pod %s will not become ready: %s", pod.Name, err)
+			return false, err
+		}
+		var ready bool
+		ready, lastStatus = podReady(current)
+		if ready {
+			log.Infof("Pod %s is ready", pod.Name)
+			return true, nil
+		}
+		log.Infof("Pod %s: %s", pod.Name, lastStatus)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the operation timed out while waiting for the Pod to become ready")
+		return false, fmt.Errorf("the operation timed out while waiting for the Pod to become ready: %s", lastStatus)
 	}
+	return err == nil, err
+}
 
-	// Create watch operation
-	objWatch, err := clientset.
-		AppsV1().
-		Deployments(deployment.ObjectMeta.Namespace).
-		Watch(context.Background(), watchOptions)
-	if err != nil {
-		log.Fatalf("error when attempting to wait for Deployment: %s", err)
-	}
-	log.Infof("waiting for %s Deployment to be ready - this could take up to %v seconds", deployment.Name, timeoutSeconds)
-
-	objChan := objWatch.ResultChan()
-	for {
-		select {
-		case event, ok := <-objChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for Deployment: %s", err)
-			}
-			if event.
-				Object.(*appsv1.Deployment).
-				Status.ReadyReplicas == configuredReplicas {
-				log.Infof("all Pods in Deployment %s are ready", deployment.Name)
-				return true, nil
-			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the Deployment was not ready within the timeout period")
-			return false, fmt.Errorf("the Deployment was not ready within the timeout period")
+// WaitForDaemonSetReady waits for a target DaemonSet to finish rolling out to every scheduled node
+func WaitForDaemonSetReady(ctx context.Context, clientset *kubernetes.Clientset, daemonSet *appsv1.DaemonSet) (bool, error) {
+	lw := newListWatch[*appsv1.DaemonSetList](ctx, clientset.AppsV1().DaemonSets(daemonSet.Namespace), nameListOptions(daemonSet.Name))
+	log.Infof("waiting for %s DaemonSet to be ready - this could take up to %v", daemonSet.Name, TimeRemaining(ctx))
+
+	lastStatus := fmt.Sprintf("DaemonSet %s does not exist", daemonSet.Name)
+	err := watchUntil(ctx, lw, &appsv1.DaemonSet{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*appsv1.DaemonSet)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			lastStatus = fmt.Sprintf("DaemonSet %s was deleted", daemonSet.Name)
+			return false, nil
 		}
+		var ready bool
+		ready, lastStatus = daemonSetReady(current)
+		if ready {
+			log.Infof("all Pods in DaemonSet %s are ready", daemonSet.Name)
+			return true, nil
+		}
+		log.Infof("DaemonSet %s: %s", daemonSet.Name, lastStatus)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the DaemonSet was not ready within the timeout period")
+		return false, fmt.Errorf("the DaemonSet was not ready within the timeout period: %s", lastStatus)
 	}
+	return err == nil, err
 }
 
-// WaitForPodReady waits for a target Pod to become ready
-func WaitForPodReady(clientset *kubernetes.Clientset, pod *v1.Pod, timeoutSeconds int64) (bool, error) {
-	// Format list for metav1.ListOptions for watch
-	watchOptions := metav1.ListOptions{
-		FieldSelector: fmt.Sprintf(
-			"metadata.name=%s", pod.Name),
-	}
-
-	// Create watch operation
-	objWatch, err := clientset.
-		CoreV1().
-		Pods(pod.ObjectMeta.Namespace).
-		Watch(context.Background(), watchOptions)
-	if err != nil {
-		log.Fatalf("error when attempting to wait for Pod: %s", err)
+// daemonSetReady reports whether the DaemonSet controller has observed the latest spec
+// and every scheduled Pod is updated and ready, along with a description of its progress
+func daemonSetReady(daemonSet *appsv1.DaemonSet) (bool, string) {
+	status := daemonSet.Status
+	if status.ObservedGeneration < daemonSet.Generation {
+		return false, "waiting for the DaemonSet spec update to be observed"
+	}
+	if status.UpdatedNumberScheduled < status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%v of %v updated Pods have been scheduled", status.UpdatedNumberScheduled, status.DesiredNumberScheduled)
 	}
-	log.Infof("waiting for %s Pod to be ready - this could take up to %v seconds", pod.Name, timeoutSeconds)
+	if status.NumberReady < status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%v of %v Pods are ready", status.NumberReady, status.DesiredNumberScheduled)
+	}
+	return true, fmt.Sprintf("%v of %v Pods are ready", status.NumberReady, status.DesiredNumberScheduled)
+}
 
-	// Feed events using provided channel
-	objChan := objWatch.ResultChan()
+// WaitForPersistentVolumeClaimBound waits for a target PersistentVolumeClaim to be bound
+// If it is not bound within the timeout, the provisioning events and StorageClass are reported
+func WaitForPersistentVolumeClaimBound(ctx context.Context, clientset *kubernetes.Clientset, pvc *v1.PersistentVolumeClaim) (bool, error) {
+	lw := newListWatch[*v1.PersistentVolumeClaimList](ctx, clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace), nameListOptions(pvc.Name))
+	log.Infof("waiting for %s PersistentVolumeClaim to be bound - this could take up to %v", pvc.Name, TimeRemaining(ctx))
+
+	current := pvc
+	err := watchUntil(ctx, lw, &v1.PersistentVolumeClaim{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		claim, ok := event.Object.(*v1.PersistentVolumeClaim)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		current = claim
+		switch current.Status.Phase {
+		case v1.ClaimBound:
+			log.Infof("PersistentVolumeClaim %s is bound to PersistentVolume %s", pvc.Name, current.Spec.VolumeName)
+			return true, nil
+		case v1.ClaimLost:
+			return false, fmt.Errorf("the PersistentVolumeClaim %s has lost its PersistentVolume %s", pvc.Name, current.Spec.VolumeName)
+		}
+		log.Infof("PersistentVolumeClaim %s is %s", pvc.Name, current.Status.Phase)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the PersistentVolumeClaim was not bound within the timeout period")
+		return false, fmt.Errorf("the PersistentVolumeClaim was not bound within the timeout period: %s", persistentVolumeClaimDiagnostics(clientset, current))
+	}
+	return err == nil, err
+}
 
-	// Listen until the Pod is ready
-	// Timeout if it isn't ready within timeoutSeconds
-	for {
-		select {
-		case event, ok := <-objChan:
-			if !ok {
-				// Error if the channel closes
-				log.Error("fail")
+// persistentVolumeClaimDiagnostics describes the StorageClass and provisioning events of a
+// PersistentVolumeClaim to explain why it is not bound
+func persistentVolumeClaimDiagnostics(clientset *kubernetes.Clientset, pvc *v1.PersistentVolumeClaim) string {
+	var diagnostics []string
+
+	storageClassName := "the default StorageClass"
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == "" {
+		storageClassName = "no StorageClass, a matching PersistentVolume has to be created manually"
+	} else if pvc.Spec.StorageClassName != nil {
+		storageClassName = fmt.Sprintf("StorageClass %s", *pvc.Spec.StorageClassName)
+		storageClass, err := clientset.StorageV1().StorageClasses().Get(context.Background(), *pvc.Spec.StorageClassName, metav1.GetOptions{})
+		if err != nil {
+			storageClassName = fmt.Sprintf("%s (%s)", storageClassName, err)
+		} else {
+			bindingMode := "Immediate"
+			if storageClass.VolumeBindingMode != nil {
+				bindingMode = string(*storageClass.VolumeBindingMode)
 			}
-			if event.
-				Object.(*v1.Pod).
-				Status.
-				Phase == "Running" {
-				log.Infof("Pod %s is %s", pod.Name, event.Object.(*v1.Pod).Status.Phase)
-				return true, nil
+			storageClassName = fmt.Sprintf("%s (provisioner %s, volumeBindingMode %s)", storageClassName, storageClass.Provisioner, bindingMode)
+		}
+	}
+	diagnostics = append(diagnostics, fmt.Sprintf("phase %s using %s", pvc.Status.Phase, storageClassName))
+
+	events, err := clientset.CoreV1().Events(pvc.Namespace).List(context.Background(), metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=PersistentVolumeClaim,involvedObject.name=%s", pvc.Name),
+	})
+	if err != nil {
+		diagnostics = append(diagnostics, fmt.Sprintf("error listing events: %s", err))
+	} else {
+		for _, event := range events.Items {
+			if event.Type == v1.EventTypeWarning || event.Reason == "ProvisioningFailed" || event.Reason == "WaitForFirstConsumer" {
+				diagnostics = append(diagnostics, fmt.Sprintf("%s: %s", event.Reason, event.Message))
 			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the operation timed out while waiting for the Pod to become ready")
-			return false, fmt.Errorf("the operation timed out while waiting for the Pod to become ready")
 		}
 	}
+
+	return strings.Join(diagnostics, "; ")
 }
 
 // WaitForStatefulSetReady waits for a target StatefulSet to become ready
-func WaitForStatefulSetReady(clientset *kubernetes.Clientset, statefulset *appsv1.StatefulSet, timeoutSeconds int64, ignoreReady bool) (bool, error) {
+func WaitForStatefulSetReady(ctx context.Context, clientset *kubernetes.Clientset, statefulset *appsv1.StatefulSet, ignoreReady bool) (bool, error) {
+	lw := newListWatch[*appsv1.StatefulSetList](ctx, clientset.AppsV1().StatefulSets(statefulset.Namespace), nameListOptions(statefulset.Name))
+	log.Infof("waiting for %s StatefulSet to be ready - this could take up to %v", statefulset.Name, TimeRemaining(ctx))
+
+	lastStatus := fmt.Sprintf("StatefulSet %s does not exist", statefulset.Name)
+	err := watchUntil(ctx, lw, &appsv1.StatefulSet{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*appsv1.StatefulSet)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		if !ignoreReady {
+			// Under normal circumstances, once all Pods are ready
+			// return success
+			var ready bool
+			ready, lastStatus = statefulSetReady(current)
+			if ready {
+				log.Infof("all Pods in StatefulSet %s are ready", statefulset.Name)
+			}
+			return ready, nil
+		}
 
-	// Format list for metav1.ListOptions for watch
-	configuredReplicas := statefulset.Status.Replicas
+		// Under circumstances where Pods may be running but not ready
+		// These may require additional setup before use, etc.
+		var rolledOut bool
+		rolledOut, lastStatus = statefulSetCurrent(current)
+		if !rolledOut {
+			return false, nil
+		}
+		// Get Pods owned by the StatefulSet
+		pods, err := clientset.CoreV1().Pods(statefulset.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("controller-revision-hash=%s", current.Status.CurrentRevision),
+		})
+		if err != nil {
+			return false, fmt.Errorf("could not find Pods owned by StatefulSet %s: %s", statefulset.Name, err)
+		}
 
-	// Create watch operation
-	objWatch, err := clientset.AppsV1().StatefulSets(statefulset.ObjectMeta.Namespace).Watch(context.Background(), metav1.ListOptions{
-		FieldSelector: fmt.Sprintf(
-			"metadata.name=%s", statefulset.Name),
-	})
-	if err != nil {
-		log.Fatalf("error when attempting to wait for StatefulSet: %s", err)
-	}
-	log.Infof("waiting for %s StatefulSet to be ready - this could take up to %v seconds", statefulset.Name, timeoutSeconds)
-
-	objChan := objWatch.ResultChan()
-	for {
-		select {
-		case event, ok := <-objChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for StatefulSet: %s", err)
+		// Determine when the Pods are running
+		for _, pod := range pods.Items {
+			if err := watchForStatefulSetPodReady(ctx, clientset, statefulset.Namespace, pod.Name); err != nil {
+				return false, err
 			}
-			if ignoreReady {
-				// Under circumstances where Pods may be running but not ready
-				// These may require additional setup before use, etc.
-				currentRevision := event.Object.(*appsv1.StatefulSet).Status.CurrentRevision
-				if event.Object.(*appsv1.StatefulSet).Status.CurrentReplicas == configuredReplicas {
-					// Get Pods owned by the StatefulSet
-					pods, err := clientset.CoreV1().Pods(statefulset.ObjectMeta.Namespace).List(context.Background(), metav1.ListOptions{
-						LabelSelector: fmt.Sprintf("controller-revision-hash=%s", currentRevision),
-					})
-					if err != nil {
-						log.Fatalf("could not find Pods owned by StatefulSet")
-					}
-
-					// Determine when the Pods are running
-					for _, pod := range pods.Items {
-						err := watchForStatefulSetPodReady(clientset, statefulset.Namespace, statefulset.Name, pod.Name, timeoutSeconds)
-						if err != nil {
-							log.Fatalf(err.Error())
-						}
-						log.Infof("pod %s in statefulset %s is running", pod.Name, statefulset.Name)
-					}
-					objWatch.Stop()
-					return true, nil
-				}
-			} else {
-				// Under normal circumstances, once all Pods are ready
-				// return success
-				if event.Object.(*appsv1.StatefulSet).Status.AvailableReplicas == configuredReplicas {
-					log.Infof("all Pods in StatefulSet %s are ready", statefulset.Name)
-					objWatch.Stop()
-					return true, nil
-				}
-			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the StatefulSet was not ready within the timeout period")
-			return false, fmt.Errorf("the StatefulSet was not ready within the timeout period")
+			log.Infof("pod %s in statefulset %s is running", pod.Name, statefulset.Name)
 		}
+		return true, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the StatefulSet was not ready within the timeout period")
+		return false, fmt.Errorf("the StatefulSet was not ready within the timeout period: %s", lastStatus)
+	}
+	return err == nil, err
+}
+
+// statefulSetReplicas returns the number of replicas requested by the StatefulSet spec
+func statefulSetReplicas(statefulSet *appsv1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		return 1
 	}
+	return *statefulSet.Spec.Replicas
 }
 
-// watchForStatefulSetPodReady inspects a Pod associated with a StatefulSet and
-// uses a channel to determine when it's ready
-// The channel will timeout if the Pod isn't ready by timeoutSeconds
-func watchForStatefulSetPodReady(clientset *kubernetes.Clientset, namespace string, statefulSetName string, podName string, timeoutSeconds int64) error {
-	podObjWatch, err := clientset.CoreV1().Pods(namespace).Watch(context.Background(), metav1.ListOptions{
-		FieldSelector: fmt.Sprintf(
-			"metadata.name=%s", podName),
+// statefulSetReady reports whether the StatefulSet controller has observed the latest spec
+// and every requested replica is available, along with a description of its progress
+func statefulSetReady(statefulSet *appsv1.StatefulSet) (bool, string) {
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return false, "waiting for the StatefulSet spec update to be observed"
+	}
+	replicas := statefulSetReplicas(statefulSet)
+	status := fmt.Sprintf("%v of %v replicas are available", statefulSet.Status.AvailableReplicas, replicas)
+	return statefulSet.Status.AvailableReplicas >= replicas, status
+}
+
+// statefulSetCurrent reports whether the StatefulSet controller has observed the latest spec
+// and every requested replica runs the current revision, along with a description of its progress
+func statefulSetCurrent(statefulSet *appsv1.StatefulSet) (bool, string) {
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return false, "waiting for the StatefulSet spec update to be observed"
+	}
+	replicas := statefulSetReplicas(statefulSet)
+	status := fmt.Sprintf("%v of %v replicas are current", statefulSet.Status.CurrentReplicas, replicas)
+	return statefulSet.Status.CurrentReplicas >= replicas, status
+}
+
+// watchForStatefulSetPodReady watches a Pod associated with a StatefulSet until
+// all of its containers are running
+// This is used when Pods are expected to run without being ready, so the Ready
+// condition is not checked, but it still fails early on unrecoverable container errors
+func watchForStatefulSetPodReady(ctx context.Context, clientset *kubernetes.Clientset, namespace string, podName string) error {
+	lw := newListWatch[*v1.PodList](ctx, clientset.CoreV1().Pods(namespace), nameListOptions(podName))
+	err := watchUntil(ctx, lw, &v1.Pod{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		if err := podFailure(pod); err != nil {
+			return false, err
+		}
+		return podRunning(pod), nil
 	})
-	if err != nil {
-		log.Fatalf("error when attempting to wait for Pod: %s", err)
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the StatefulSet Pod was not ready within the timeout period")
+		return errors.New("the StatefulSet Pod was not ready within the timeout period")
 	}
+	return err
+}
 
-	podObjChan := podObjWatch.ResultChan()
-	for {
-		select {
-		case podEvent, ok := <-podObjChan:
-			time.Sleep(time.Second * 1)
-			if !ok {
-				// Error if the channel closes
-				log.Fatalf("error waiting for Pod: %s", err)
-			}
-			if podEvent.Object.(*v1.Pod).Status.Phase == "Running" {
-				podObjWatch.Stop()
-				return nil
-			}
-		case <-time.After(time.Duration(timeoutSeconds) * time.Second):
-			log.Error("the StatefulSet Pod was not ready within the timeout period")
-			return errors.New("the StatefulSet Pod was not ready within the timeout period")
+// oomKilledRestartThreshold is the number of restarts after which a container
+// that keeps getting OOMKilled is considered to be failing
+const oomKilledRestartThreshold int32 = 3
+
+// failingContainerReasons are container waiting reasons that will not resolve on their own
+var failingContainerReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"CreateContainerConfigError": true,
+}
+
+// podReady reports whether the Pod Ready condition is true and every container
+// is ready, along with a description of its progress
+func podReady(pod *v1.Pod) (bool, string) {
+	var readyContainers int
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			readyContainers++
+		}
+	}
+	progress := fmt.Sprintf("%s with %v of %v containers ready", pod.Status.Phase, readyContainers, len(pod.Spec.Containers))
+	if readyContainers < len(pod.Spec.Containers) {
+		return false, progress
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue, progress
 		}
 	}
+	return false, progress
+}
+
+// podRunning reports whether a Pod is running with all of its containers started
+func podRunning(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// podFailure returns an error describing why a Pod will not become ready without
+// intervention, or nil if it may still become ready
+func podFailure(pod *v1.Pod) error {
+	switch pod.Status.Phase {
+	case v1.PodFailed:
+		return fmt.Errorf("pod %s failed: %s %s", pod.Name, pod.Status.Reason, pod.Status.Message)
+	case v1.PodSucceeded:
+		return fmt.Errorf("pod %s has already completed and will not become ready", pod.Name)
+	}
+
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && failingContainerReasons[waiting.Reason] {
+			return fmt.Errorf("container %s in pod %s is in %s: %s", status.Name, pod.Name, waiting.Reason, waiting.Message)
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil &&
+			terminated.Reason == "OOMKilled" && status.RestartCount >= oomKilledRestartThreshold {
+			return fmt.Errorf("container %s in pod %s has been OOMKilled and restarted %v times", status.Name, pod.Name, status.RestartCount)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,392 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		wantDone   bool
+		wantErr    bool
+	}{
+		{
+			name: "rollout complete",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					ReadyReplicas:      3,
+					AvailableReplicas:  3,
+				},
+			},
+			wantDone: true,
+		},
+		{
+			name: "scaled to zero",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(0)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2},
+			},
+			wantDone: true,
+		},
+		{
+			name: "spec update not observed",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			},
+		},
+		{
+			name: "mid rollout with all old replicas ready",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    1,
+					ReadyReplicas:      4,
+					AvailableReplicas:  4,
+				},
+			},
+		},
+		{
+			name: "old replicas pending termination",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  4,
+				},
+			},
+		},
+		{
+			name: "updated replicas not available",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  2,
+				},
+			},
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    1,
+					AvailableReplicas:  2,
+					Conditions: []appsv1.DeploymentCondition{
+						{
+							Type:    appsv1.DeploymentProgressing,
+							Status:  v1.ConditionFalse,
+							Reason:  "ProgressDeadlineExceeded",
+							Message: "ReplicaSet has timed out progressing.",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, _, err := deploymentRolloutStatus(tt.deployment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deploymentRolloutStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if done != tt.wantDone {
+				t.Errorf("deploymentRolloutStatus() done = %v, want %v", done, tt.wantDone)
+			}
+		})
+	}
+}
+
+func TestPodReady(t *testing.T) {
+	spec := v1.PodSpec{Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}}}
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want bool
+	}{
+		{
+			name: "running with all containers ready",
+			pod: &v1.Pod{
+				Spec: spec,
+				Status: v1.PodStatus{
+					Phase:             v1.PodRunning,
+					Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+					ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true}, {Name: "sidecar", Ready: true}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "running with a container not ready",
+			pod: &v1.Pod{
+				Spec: spec,
+				Status: v1.PodStatus{
+					Phase:             v1.PodRunning,
+					Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}},
+					ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true}, {Name: "sidecar", Ready: false}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "running without a ready condition",
+			pod: &v1.Pod{
+				Spec: spec,
+				Status: v1.PodStatus{
+					Phase:             v1.PodRunning,
+					ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true}, {Name: "sidecar", Ready: true}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "pending",
+			pod:  &v1.Pod{Spec: spec, Status: v1.PodStatus{Phase: v1.PodPending}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := podReady(tt.pod); got != tt.want {
+				t.Errorf("podReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  v1.PodStatus
+		wantErr bool
+	}{
+		{
+			name: "starting container",
+			status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+				},
+			},
+		},
+		{
+			name: "crash loop",
+			status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "init container image pull failure",
+			status: v1.PodStatus{
+				Phase: v1.PodPending,
+				InitContainerStatuses: []v1.ContainerStatus{
+					{Name: "init", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "single OOMKill",
+			status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:                 "app",
+						RestartCount:         1,
+						State:                v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+						LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled"}},
+					},
+				},
+			},
+		},
+		{
+			name: "repeated OOMKills",
+			status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:                 "app",
+						RestartCount:         3,
+						State:                v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+						LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "failed pod",
+			status:  v1.PodStatus{Phase: v1.PodFailed, Reason: "Evicted"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := podFailure(&v1.Pod{Status: tt.status}); (err != nil) != tt.wantErr {
+				t.Errorf("podFailure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDaemonSetReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		daemonSet *appsv1.DaemonSet
+		want      bool
+	}{
+		{
+			name: "all Pods updated and ready",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: appsv1.DaemonSetStatus{
+					ObservedGeneration:     2,
+					DesiredNumberScheduled: 3,
+					UpdatedNumberScheduled: 3,
+					NumberReady:            3,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "spec update not observed",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status: appsv1.DaemonSetStatus{
+					ObservedGeneration:     2,
+					DesiredNumberScheduled: 3,
+					UpdatedNumberScheduled: 3,
+					NumberReady:            3,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "updated Pods not scheduled",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: appsv1.DaemonSetStatus{
+					ObservedGeneration:     2,
+					DesiredNumberScheduled: 3,
+					UpdatedNumberScheduled: 1,
+					NumberReady:            3,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "Pods not ready",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: appsv1.DaemonSetStatus{
+					ObservedGeneration:     2,
+					DesiredNumberScheduled: 3,
+					UpdatedNumberScheduled: 3,
+					NumberReady:            2,
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := daemonSetReady(tt.daemonSet); got != tt.want {
+				t.Errorf("daemonSetReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatefulSetReady(t *testing.T) {
+	tests := []struct {
+		name        string
+		statefulSet *appsv1.StatefulSet
+		want        bool
+	}{
+		{
+			name: "new StatefulSet with an empty status",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+			},
+			want: false,
+		},
+		{
+			name: "all replicas available",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, AvailableReplicas: 3},
+			},
+			want: true,
+		},
+		{
+			name: "spec update not observed",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(5)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, AvailableReplicas: 3},
+			},
+			want: false,
+		},
+		{
+			name: "replicas not available",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, AvailableReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "default of one replica",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 1, AvailableReplicas: 1},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := statefulSetReady(tt.statefulSet); got != tt.want {
+				t.Errorf("statefulSetReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultJobBackoffLimit is the backoffLimit Kubernetes applies when it is not set
+const defaultJobBackoffLimit int32 = 6
+
+// ReturnJobObject returns a matching batchv1.Job object based on the selector
+func ReturnJobObject(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string) (*batchv1.Job, error) {
+	lw := newListWatch[*batchv1.JobList](ctx, clientset.BatchV1().Jobs(namespace), selector.ListOptions())
+	obj, err := waitForObject(ctx, lw, &batchv1.Job{}, "Job", selector)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*batchv1.Job), nil
+}
+
+// WaitForJobComplete waits for a target Job to complete
+// It fails as soon as the Job reports the Failed condition or has exhausted its backoffLimit,
+// printing the last log lines of the failed Pods
+func WaitForJobComplete(ctx context.Context, clientset *kubernetes.Clientset, job *batchv1.Job, logLines int64) (bool, error) {
+	lw := newListWatch[*batchv1.JobList](ctx, clientset.BatchV1().Jobs(job.Namespace), nameListOptions(job.Name))
+	log.Infof("waiting for %s Job to complete - this could take up to %v", job.Name, TimeRemaining(ctx))
+
+	err := watchUntil(ctx, lw, &batchv1.Job{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*batchv1.Job)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		complete, failure := jobFinished(current)
+		if complete {
+			log.Infof("Job %s completed", job.Name)
+			return true, nil
+		}
+		if failure != "" {
+			log.Errorf("Job %s failed: %s", job.Name, failure)
+			printFailedJobPodLogs(ctx, clientset, current, logLines)
+			return false, fmt.Errorf("the Job %s failed: %s", job.Name, failure)
+		}
+		log.Infof("Job %s: %v active, %v succeeded, %v failed", job.Name, current.Status.Active, current.Status.Succeeded, current.Status.Failed)
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the Job did not complete within the timeout period")
+		return false, fmt.Errorf("the Job did not complete within the timeout period")
+	}
+	return err == nil, err
+}
+
+// jobFinished reports whether a Job has completed or, if it has failed, the reason it failed
+func jobFinished(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, ""
+		case batchv1.JobFailed:
+			return false, fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+
+	backoffLimit := defaultJobBackoffLimit
+	if job.Spec.BackoffLimit != nil {
+		backoffLimit = *job.Spec.BackoffLimit
+	}
+	if job.Status.Failed > backoffLimit {
+		return false, fmt.Sprintf("BackoffLimitExceeded: %v Pods failed with a backoffLimit of %v", job.Status.Failed, backoffLimit)
+	}
+
+	return false, ""
+}
+
+// printFailedJobPodLogs logs the last lines of every failed container in the Pods owned by a Job
+// Pods of Jobs with restartPolicy OnFailure are restarted in place, so the logs of containers
+// that failed before their last restart are read from the previous instance
+func printFailedJobPodLogs(ctx context.Context, clientset *kubernetes.Clientset, job *batchv1.Job, logLines int64) {
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		log.Errorf("error parsing the selector of Job %s: %s", job.Name, err)
+		return
+	}
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		log.Errorf("error listing Pods owned by Job %s: %s", job.Name, err)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range failedContainers(&pod) {
+			logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+				Container: container.Name,
+				Previous:  container.Previous,
+				TailLines: &logLines,
+			}).DoRaw(ctx)
+			if err != nil {
+				log.Errorf("error retrieving logs for Pod %s container %s: %s", pod.Name, container.Name, err)
+				continue
+			}
+			log.Errorf("last %v log lines of Pod %s container %s:\n%s", logLines, pod.Name, container.Name, strings.TrimRight(string(logs), "\n"))
+		}
+	}
+}
+
+// failedContainer is a container whose logs are printed when a Job fails, Previous is set
+// when the failure happened in the instance of the container before its last restart
+type failedContainer struct {
+	Name     string
+	Previous bool
+}
+
+// failedContainers returns the containers of a Pod that terminated with a non-zero exit code,
+// either currently or before their last restart
+// If none can be found in a failed Pod, every container in the Pod is returned
+func failedContainers(pod *v1.Pod) []failedContainer {
+	var containers []failedContainer
+	for _, status := range pod.Status.ContainerStatuses {
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+			containers = append(containers, failedContainer{Name: status.Name})
+		case status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0:
+			containers = append(containers, failedContainer{Name: status.Name, Previous: true})
+		}
+	}
+	if len(containers) == 0 && pod.Status.Phase == v1.PodFailed {
+		for _, container := range pod.Spec.Containers {
+			containers = append(containers, failedContainer{Name: container.Name})
+		}
+	}
+	return containers
+}
@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestJobFinished(t *testing.T) {
+	tests := []struct {
+		name       string
+		job        *batchv1.Job
+		wantDone   bool
+		wantReason string
+	}{
+		{
+			name: "complete",
+			job: &batchv1.Job{Status: batchv1.JobStatus{
+				Succeeded:  1,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}},
+			}},
+			wantDone: true,
+		},
+		{
+			name: "failed",
+			job: &batchv1.Job{Status: batchv1.JobStatus{
+				Failed: 1,
+				Conditions: []batchv1.JobCondition{{
+					Type:    batchv1.JobFailed,
+					Status:  v1.ConditionTrue,
+					Reason:  "DeadlineExceeded",
+					Message: "Job was active longer than specified deadline",
+				}},
+			}},
+			wantReason: "DeadlineExceeded: Job was active longer than specified deadline",
+		},
+		{
+			name: "backoff limit exceeded before the Failed condition is set",
+			job: &batchv1.Job{
+				Spec:   batchv1.JobSpec{BackoffLimit: int32Ptr(2)},
+				Status: batchv1.JobStatus{Failed: 3},
+			},
+			wantReason: "BackoffLimitExceeded: 3 Pods failed with a backoffLimit of 2",
+		},
+		{
+			name: "failures within the default backoff limit",
+			job:  &batchv1.Job{Status: batchv1.JobStatus{Failed: 6}},
+		},
+		{
+			name: "condition not true",
+			job: &batchv1.Job{Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionFalse}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, reason := jobFinished(tt.job)
+			if done != tt.wantDone || reason != tt.wantReason {
+				t.Errorf("jobFinished() = %v, %q, want %v, %q", done, reason, tt.wantDone, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFailedContainers(t *testing.T) {
+	terminated := func(exitCode int32) v1.ContainerState {
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: exitCode}}
+	}
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want []failedContainer
+	}{
+		{
+			name: "failed Pod",
+			pod: &v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, ContainerStatuses: []v1.ContainerStatus{
+				{Name: "terraform", State: terminated(1)},
+				{Name: "sidecar", State: terminated(0)},
+			}}},
+			want: []failedContainer{{Name: "terraform"}},
+		},
+		{
+			name: "restarted with OnFailure",
+			pod: &v1.Pod{Status: v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{
+				{Name: "terraform", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, LastTerminationState: terminated(1)},
+			}}},
+			want: []failedContainer{{Name: "terraform", Previous: true}},
+		},
+		{
+			name: "failed Pod without a terminated container",
+			pod: &v1.Pod{
+				Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "terraform"}}},
+				Status: v1.PodStatus{Phase: v1.PodFailed},
+			},
+			want: []failedContainer{{Name: "terraform"}},
+		},
+		{
+			name: "running Pod that never failed",
+			pod: &v1.Pod{
+				Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "terraform"}}},
+				Status: v1.PodStatus{Phase: v1.PodRunning},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedContainers(tt.pod); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failedContainers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
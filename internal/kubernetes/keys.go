@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WaitForSecretKey waits for a Secret key to be set to a value matching the options
+func WaitForSecretKey(ctx context.Context, clientset *kubernetes.Clientset, o *KeyWaitOptions) error {
+	lw := newListWatch[*v1.SecretList](ctx, clientset.CoreV1().Secrets(o.Namespace), nameListOptions(o.Name))
+	return waitForKey(ctx, lw, &v1.Secret{}, "Secret", o, func(obj runtime.Object) (map[string]string, bool) {
+		secret, ok := obj.(*v1.Secret)
+		if !ok {
+			return nil, false
+		}
+		data := make(map[string]string)
+		for key, value := range secret.StringData {
+			data[key] = value
+		}
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+		return data, true
+	})
+}
+
+// WaitForConfigMapKey waits for a ConfigMap key to be set to a value matching the options
+func WaitForConfigMapKey(ctx context.Context, clientset *kubernetes.Clientset, o *KeyWaitOptions) error {
+	lw := newListWatch[*v1.ConfigMapList](ctx, clientset.CoreV1().ConfigMaps(o.Namespace), nameListOptions(o.Name))
+	return waitForKey(ctx, lw, &v1.ConfigMap{}, "ConfigMap", o, func(obj runtime.Object) (map[string]string, bool) {
+		configMap, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return nil, false
+		}
+		data := make(map[string]string)
+		for key, value := range configMap.BinaryData {
+			data[key] = string(value)
+		}
+		for key, value := range configMap.Data {
+			data[key] = value
+		}
+		return data, true
+	})
+}
+
+// waitForKey watches a Secret or ConfigMap until the data returned by objectData
+// contains a key matching the options
+func waitForKey(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, kind string, o *KeyWaitOptions, objectData func(runtime.Object) (map[string]string, bool)) error {
+	var valueRegex *regexp.Regexp
+	if o.ValueRegex != "" {
+		var err error
+		valueRegex, err = regexp.Compile(o.ValueRegex)
+		if err != nil {
+			return fmt.Errorf("please check the provided value regex %s: %s", o.ValueRegex, err)
+		}
+	}
+	log.Infof("waiting for %s %s to contain key %s - this could take up to %v", kind, o.Name, o.Key, TimeRemaining(ctx))
+
+	lastCondition := fmt.Sprintf("%s %s does not exist", kind, o.Name)
+	err := watchUntil(ctx, lw, objType, watchOptions{}, func(event watch.Event) (bool, error) {
+		data, ok := objectData(event.Object)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			lastCondition = fmt.Sprintf("%s %s was deleted", kind, o.Name)
+			return false, nil
+		}
+
+		var matched bool
+		matched, lastCondition = keyValueMatches(data, o.Key, o.Value, valueRegex)
+		if matched {
+			log.Infof("%s %s key %s validated", kind, o.Name, o.Key)
+			return true, nil
+		}
+		log.Infof("%s %s %s", kind, o.Name, lastCondition)
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		log.Errorf("the %s key was not set within the timeout period", kind)
+		return fmt.Errorf("timed out waiting for %s %s key %s: %s", kind, o.Name, o.Key, lastCondition)
+	case err != nil:
+		return fmt.Errorf("error waiting for %s %s: %s", kind, o.Name, err)
+	}
+	return nil
+}
+
+// keyValueMatches reports whether key is set in data to value, to a value matching valueRegex,
+// or to any non-empty value when neither is provided, along with the reason when it is not
+func keyValueMatches(data map[string]string, key string, value string, valueRegex *regexp.Regexp) (bool, string) {
+	actual, ok := data[key]
+	switch {
+	case !ok:
+		return false, fmt.Sprintf("does not contain key %s", key)
+	case value != "":
+		if actual != value {
+			return false, fmt.Sprintf("key %s does not have the expected value", key)
+		}
+	case valueRegex != nil:
+		if !valueRegex.MatchString(actual) {
+			return false, fmt.Sprintf("key %s does not match %s", key, valueRegex)
+		}
+	case actual == "":
+		return false, fmt.Sprintf("key %s is empty", key)
+	}
+	return true, ""
+}
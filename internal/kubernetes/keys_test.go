@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestKeyValueMatches(t *testing.T) {
+	data := map[string]string{
+		"root-token": "hvs.abc123",
+		"empty":      "",
+	}
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		valueRegex string
+		want       bool
+	}{
+		{name: "key is set", key: "root-token", want: true},
+		{name: "missing key", key: "unseal-key", want: false},
+		{name: "empty value", key: "empty", want: false},
+		{name: "matching value", key: "root-token", value: "hvs.abc123", want: true},
+		{name: "different value", key: "root-token", value: "hvs.def456", want: false},
+		{name: "matching regex", key: "root-token", valueRegex: `^hvs\.`, want: true},
+		{name: "regex allows empty values", key: "empty", valueRegex: `^$`, want: true},
+		{name: "regex does not match", key: "root-token", valueRegex: `^s\.`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var valueRegex *regexp.Regexp
+			if tt.valueRegex != "" {
+				valueRegex = regexp.MustCompile(tt.valueRegex)
+			}
+			if got, _ := keyValueMatches(data, tt.key, tt.value, valueRegex); got != tt.want {
+				t.Errorf("keyValueMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForIngressAddress waits for an Ingress to be assigned a hostname or IP
+// by its ingress controller and returns it
+func WaitForIngressAddress(ctx context.Context, clientset *kubernetes.Clientset, namespace string, name string) (string, error) {
+	lw := newListWatch[*networkingv1.IngressList](ctx, clientset.NetworkingV1().Ingresses(namespace), nameListOptions(name))
+	log.Infof("waiting for Ingress %s to be assigned an address - this could take up to %v", name, TimeRemaining(ctx))
+
+	var address string
+	err := watchUntil(ctx, lw, &networkingv1.Ingress{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		ingress, ok := event.Object.(*networkingv1.Ingress)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		for _, lb := range ingress.Status.LoadBalancer.Ingress {
+			if address = loadBalancerAddress(lb.Hostname, lb.IP); address != "" {
+				log.Infof("Ingress %s was assigned address %s", name, address)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the Ingress was not assigned an address within the timeout period")
+		return "", fmt.Errorf("the Ingress was not assigned an address within the timeout period")
+	}
+	return address, err
+}
+
+// WaitForLoadBalancerAddress waits for a Service of type LoadBalancer to be assigned
+// a hostname or IP and returns it
+func WaitForLoadBalancerAddress(ctx context.Context, clientset *kubernetes.Clientset, namespace string, name string) (string, error) {
+	lw := newListWatch[*v1.ServiceList](ctx, clientset.CoreV1().Services(namespace), nameListOptions(name))
+	log.Infof("waiting for Service %s to be assigned a load balancer address - this could take up to %v", name, TimeRemaining(ctx))
+
+	var address string
+	err := watchUntil(ctx, lw, &v1.Service{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		service, ok := event.Object.(*v1.Service)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+			return false, fmt.Errorf("service %s is of type %s and will never be assigned a load balancer address", name, service.Spec.Type)
+		}
+		for _, lb := range service.Status.LoadBalancer.Ingress {
+			if address = loadBalancerAddress(lb.Hostname, lb.IP); address != "" {
+				log.Infof("Service %s was assigned address %s", name, address)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		log.Error("the Service was not assigned a load balancer address within the timeout period")
+		return "", fmt.Errorf("the Service was not assigned a load balancer address within the timeout period")
+	}
+	return address, err
+}
+
+// loadBalancerAddress returns the hostname of a load balancer ingress entry,
+// falling back to its IP
+func loadBalancerAddress(hostname string, ip string) string {
+	if hostname != "" {
+		return hostname
+	}
+	return ip
+}
@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ReturnDeploymentObjects returns every matching appsv1.Deployment object once at least minCount exist
+func ReturnDeploymentObjects(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string, minCount int) ([]appsv1.Deployment, error) {
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments(namespace), selector.ListOptions())
+	objects, err := waitForObjectCount(ctx, lw, &appsv1.Deployment{}, "Deployment", selector, minCount)
+	if err != nil {
+		return nil, err
+	}
+	deployments := make([]appsv1.Deployment, 0, len(objects))
+	for _, obj := range objects {
+		deployments = append(deployments, *obj.(*appsv1.Deployment))
+	}
+	return deployments, nil
+}
+
+// ReturnPodObjects returns every matching v1.Pod object once at least minCount exist
+func ReturnPodObjects(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string, minCount int) ([]v1.Pod, error) {
+	lw := newListWatch[*v1.PodList](ctx, clientset.CoreV1().Pods(namespace), selector.ListOptions())
+	objects, err := waitForObjectCount(ctx, lw, &v1.Pod{}, "Pod", selector, minCount)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]v1.Pod, 0, len(objects))
+	for _, obj := range objects {
+		pods = append(pods, *obj.(*v1.Pod))
+	}
+	return pods, nil
+}
+
+// ReturnStatefulSetObjects returns every matching appsv1.StatefulSet object once at least minCount exist
+func ReturnStatefulSetObjects(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string, minCount int) ([]appsv1.StatefulSet, error) {
+	lw := newListWatch[*appsv1.StatefulSetList](ctx, clientset.AppsV1().StatefulSets(namespace), selector.ListOptions())
+	objects, err := waitForObjectCount(ctx, lw, &appsv1.StatefulSet{}, "StatefulSet", selector, minCount)
+	if err != nil {
+		return nil, err
+	}
+	statefulSets := make([]appsv1.StatefulSet, 0, len(objects))
+	for _, obj := range objects {
+		statefulSets = append(statefulSets, *obj.(*appsv1.StatefulSet))
+	}
+	return statefulSets, nil
+}
+
+// ReturnDaemonSetObjects returns every matching appsv1.DaemonSet object once at least minCount exist
+func ReturnDaemonSetObjects(ctx context.Context, clientset *kubernetes.Clientset, selector ObjectSelector, namespace string, minCount int) ([]appsv1.DaemonSet, error) {
+	lw := newListWatch[*appsv1.DaemonSetList](ctx, clientset.AppsV1().DaemonSets(namespace), selector.ListOptions())
+	objects, err := waitForObjectCount(ctx, lw, &appsv1.DaemonSet{}, "DaemonSet", selector, minCount)
+	if err != nil {
+		return nil, err
+	}
+	daemonSets := make([]appsv1.DaemonSet, 0, len(objects))
+	for _, obj := range objects {
+		daemonSets = append(daemonSets, *obj.(*appsv1.DaemonSet))
+	}
+	return daemonSets, nil
+}
+
+// waitForObjectCount waits until at least minCount objects returned by lw exist and returns
+// all of them sorted by name
+func waitForObjectCount(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, kind string, selector ObjectSelector, minCount int) ([]runtime.Object, error) {
+	if minCount < 1 {
+		minCount = 1
+	}
+	log.Infof("waiting for at least %v %s objects with %s to be created", minCount, kind, selector)
+
+	objects := make(map[string]runtime.Object)
+	var synced bool
+	err := watchUntil(ctx, lw, objType, watchOptions{
+		synced: func(store cache.Store) (bool, error) {
+			synced = true
+			return len(objects) >= minCount, nil
+		},
+	}, func(event watch.Event) (bool, error) {
+		name := objectName(event.Object)
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			objects[name] = event.Object
+		case watch.Deleted:
+			delete(objects, name)
+		}
+		// Every object of the initial list is returned, not only the first minCount of them
+		if !synced {
+			return false, nil
+		}
+		if len(objects) >= minCount {
+			return true, nil
+		}
+		log.Infof("found %v of %v %s objects", len(objects), minCount, kind)
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		log.Errorf("the %s objects were not created within the timeout period", kind)
+		return nil, fmt.Errorf("only %v of %v %s objects were created within the timeout period", len(objects), minCount, kind)
+	case err != nil:
+		return nil, fmt.Errorf("error waiting for %s objects with %s to be created: %s", kind, selector, err)
+	}
+
+	result := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		result = append(result, obj)
+	}
+	sortObjectsByName(result)
+	return result, nil
+}
+
+// objectName returns the name of an object, or an empty string if it has no metadata
+func objectName(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetName()
+}
+
+// sortObjectsByName sorts objects by name so that the same object is chosen between runs
+func sortObjectsByName(objects []runtime.Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		return objectName(objects[i]) < objectName(objects[j])
+	})
+}
+
+// WaitForObjectsReady runs the wait function for every object concurrently and returns
+// once at least minReady of them are ready, reporting progress as each object finishes
+// It fails as soon as too many objects have failed for minReady to be reached
+// The context passed to wait is canceled on return, and the remaining waits are joined
+func WaitForObjectsReady(ctx context.Context, kind string, names []string, minReady int, wait func(ctx context.Context, i int) error) error {
+	if minReady > len(names) {
+		return fmt.Errorf("%v %s objects are required to be ready but only %v were found", minReady, kind, len(names))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	type result struct {
+		index int
+		err   error
+	}
+	results := make(chan result, len(names))
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results <- result{index: i, err: wait(ctx, i)}
+		}(i)
+	}
+
+	var ready, failed int
+	var lastErr error
+	for range names {
+		r := <-results
+		if r.err != nil {
+			failed++
+			lastErr = r.err
+			log.Errorf("%s %s is not ready: %s", kind, names[r.index], r.err)
+		} else {
+			ready++
+			log.Infof("%s %s is ready - %v/%v ready", kind, names[r.index], ready, minReady)
+		}
+
+		if ready >= minReady {
+			log.Infof("%v of %v %s objects are ready", ready, len(names), kind)
+			return nil
+		}
+		if len(names)-failed < minReady {
+			return fmt.Errorf("%v of %v %s objects failed, %v are required to be ready: %s", failed, len(names), kind, minReady, lastErr)
+		}
+	}
+	return fmt.Errorf("only %v of %v %s objects are ready", ready, minReady, kind)
+}
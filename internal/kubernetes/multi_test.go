@@ -0,0 +1,58 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWaitForObjectsReady(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	tests := []struct {
+		name     string
+		failing  map[string]bool
+		minReady int
+		wantErr  bool
+	}{
+		{name: "all ready", minReady: 3},
+		{name: "one failure with all required", failing: map[string]bool{"b": true}, minReady: 3, wantErr: true},
+		{name: "one failure with two required", failing: map[string]bool{"b": true}, minReady: 2},
+		{name: "two failures with two required", failing: map[string]bool{"a": true, "c": true}, minReady: 2, wantErr: true},
+		{name: "more required than found", minReady: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := WaitForObjectsReady(context.Background(), "Pod", names, tt.minReady, func(_ context.Context, i int) error {
+				if tt.failing[names[i]] {
+					return fmt.Errorf("failed")
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WaitForObjectsReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForObjectsReadyCancelsRemainingWaits(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	var canceled int32
+	err := WaitForObjectsReady(context.Background(), "Pod", names, 1, func(ctx context.Context, i int) error {
+		if names[i] == "a" {
+			return nil
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&canceled, 1)
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The remaining waits are joined before returning
+	if got := atomic.LoadInt32(&canceled); got != 2 {
+		t.Errorf("expected the 2 remaining waits to be canceled, got %v", got)
+	}
+}
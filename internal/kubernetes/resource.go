@@ -0,0 +1,378 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ReturnResourceInterface uses API discovery to resolve an apiVersion and kind
+// to a dynamic client for that resource
+// Discovery is retried until the kind is served, as the CustomResourceDefinition providing
+// it is often installed by the same rollout
+func ReturnResourceInterface(ctx context.Context, restConfig *rest.Config, apiVersion string, kind string, namespace string) (dynamic.ResourceInterface, error) {
+	return resourceInterface(ctx, restConfig, apiVersion, kind, namespace, true)
+}
+
+// resourceInterface resolves an apiVersion and kind to a dynamic client for that resource
+// Unless waitUntilServed is set, a kind that is not served is reported with an error matched
+// by meta.IsNoMatchError instead of being retried
+func resourceInterface(ctx context.Context, restConfig *rest.Config, apiVersion string, kind string, namespace string, waitUntilServed bool) (dynamic.ResourceInterface, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing apiVersion %s: %s", apiVersion, err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating discovery client: %s", err)
+	}
+	mapping, err := waitForRESTMapping(ctx, discoveryClient, gv.WithKind(kind), waitUntilServed)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %s", err)
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if namespace == "" {
+			return nil, fmt.Errorf("%s is a namespaced resource, please provide a namespace", kind)
+		}
+		return dynamicClient.Resource(mapping.Resource).Namespace(namespace), nil
+	}
+	return dynamicClient.Resource(mapping.Resource), nil
+}
+
+// waitForRESTMapping resolves a kind to its resource, retrying discovery on transient errors
+// and, when waitUntilServed is set, until the kind is served
+func waitForRESTMapping(ctx context.Context, discoveryClient discovery.DiscoveryInterface, gvk schema.GroupVersionKind, waitUntilServed bool) (*meta.RESTMapping, error) {
+	var mapping *meta.RESTMapping
+	var lastErr error
+	err := pollUntil(ctx, func(ctx context.Context) (bool, error) {
+		mapping, lastErr = discoverRESTMapping(discoveryClient, gvk)
+		if meta.IsNoMatchError(lastErr) {
+			if !waitUntilServed {
+				return true, nil
+			}
+			log.Infof("waiting for %s %s to be served by the API", gvk.GroupVersion(), gvk.Kind)
+			return false, nil
+		}
+		return lastErr == nil, lastErr
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		return nil, fmt.Errorf("timed out waiting for %s %s to be served: %s", gvk.GroupVersion(), gvk.Kind, lastErr)
+	case err != nil:
+		return nil, fmt.Errorf("error finding resource for %s %s: %s", gvk.GroupVersion(), gvk.Kind, err)
+	case lastErr != nil:
+		// The kind is not served and waitUntilServed is not set
+		return nil, lastErr
+	}
+	return mapping, nil
+}
+
+// discoverRESTMapping resolves a kind to its resource using API discovery
+// Groups that fail discovery, such as an unavailable metrics API, are skipped
+func discoverRESTMapping(discoveryClient discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("error discovering API resources: %s", err)
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources).RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// WaitForResourceReady waits for every resource matching the provided name or selector
+// to satisfy a status condition or a JSONPath expression
+func WaitForResourceReady(ctx context.Context, restConfig *rest.Config, o *ResourceWaitOptions) error {
+	if o.Condition == "" && o.JSONPath == "" {
+		return fmt.Errorf("either a condition or a jsonpath expression is required")
+	}
+	conditionType, conditionStatus := ParseConditionTarget(o.Condition)
+	var expression *jsonpath.JSONPath
+	if o.JSONPath != "" {
+		var err error
+		expression, err = parseJSONPath(o.JSONPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Filter
+	selector := ObjectSelector{
+		Name:          o.Name,
+		LabelSelector: o.Selector,
+		FieldSelector: o.FieldSelector,
+	}
+	if err := selector.Validate(); err != nil {
+		return err
+	}
+	listOptions := selector.ListOptions()
+	target := selector.String()
+
+	resource, err := ReturnResourceInterface(ctx, restConfig, o.APIVersion, o.Kind, o.Namespace)
+	if err != nil {
+		return err
+	}
+
+	lw := newListWatch[*unstructured.UnstructuredList](ctx, resource, listOptions)
+	log.Infof("waiting for %s %s - this could take up to %v", o.Kind, target, TimeRemaining(ctx))
+
+	// Track every matching object so that all of them have to be ready, readiness is only
+	// decided once the initial list has been delivered so that no existing object is missed
+	ready := make(map[string]bool)
+	var lastDetail string
+	var synced bool
+	allReady := func() bool {
+		if !allResourcesReady(ready) {
+			return false
+		}
+		log.Infof("all %s resources matching %s are ready", o.Kind, target)
+		return true
+	}
+	err = watchUntil(ctx, lw, &unstructured.Unstructured{}, watchOptions{
+		kind: o.Kind,
+		synced: func(store cache.Store) (bool, error) {
+			synced = true
+			return allReady(), nil
+		},
+	}, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false, nil
+		}
+		// The deleted object may have been the only one that was not ready yet
+		if event.Type == watch.Deleted {
+			delete(ready, obj.GetName())
+			return synced && allReady(), nil
+		}
+
+		var met bool
+		if o.Condition != "" {
+			met, lastDetail = resourceConditionMet(obj, conditionType, conditionStatus)
+		} else {
+			met, lastDetail = resourceJSONPathMatches(obj, expression, o.JSONPathValue)
+		}
+		ready[obj.GetName()] = met
+		log.Infof("%s %s: %s", o.Kind, obj.GetName(), lastDetail)
+
+		return synced && allReady(), nil
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		log.Errorf("the %s was not ready within the timeout period", o.Kind)
+		return fmt.Errorf("timed out waiting for %s %s: %s", o.Kind, target, lastDetail)
+	case err != nil:
+		return fmt.Errorf("error waiting for %s %s: %s", o.Kind, target, err)
+	}
+	return nil
+}
+
+// ParseConditionTarget splits a condition in the form Type or Type=Status,
+// the status defaults to True
+func ParseConditionTarget(condition string) (string, string) {
+	conditionType, conditionStatus, found := strings.Cut(condition, "=")
+	if !found || conditionStatus == "" {
+		conditionStatus = string(metav1.ConditionTrue)
+	}
+	return conditionType, conditionStatus
+}
+
+// parseJSONPath parses a JSONPath expression, the surrounding braces are optional
+func parseJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = fmt.Sprintf("{%s}", expression)
+	}
+	jp := jsonpath.New("wait-for").AllowMissingKeys(true)
+	if err := jp.Parse(expression); err != nil {
+		return nil, fmt.Errorf("error parsing jsonpath expression %s: %s", expression, err)
+	}
+	return jp, nil
+}
+
+// resourceConditionMet reports whether an object has a status condition with the
+// provided type and status, along with a description of the condition
+func resourceConditionMet(obj *unstructured.Unstructured, conditionType string, conditionStatus string) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if fmt.Sprint(condition["type"]) != conditionType {
+			continue
+		}
+		status := fmt.Sprint(condition["status"])
+		detail := fmt.Sprintf("condition %s is %s", conditionType, status)
+		if reason, ok := condition["reason"]; ok {
+			detail = fmt.Sprintf("%s - %v", detail, reason)
+			if message := conditionMessage(condition); message != "" {
+				detail = fmt.Sprintf("%s: %s", detail, message)
+			}
+		}
+		return strings.EqualFold(status, conditionStatus), detail
+	}
+	return false, fmt.Sprintf("condition %s not found", conditionType)
+}
+
+// conditionMessage returns the message of a status condition, or an empty string when it has none
+func conditionMessage(condition map[string]interface{}) string {
+	message, _ := condition["message"].(string)
+	return message
+}
+
+// resourceJSONPathMatches reports whether a JSONPath expression evaluated against
+// an object is equal to the provided value
+func resourceJSONPathMatches(obj *unstructured.Unstructured, expression *jsonpath.JSONPath, value string) (bool, string) {
+	var buf bytes.Buffer
+	if err := expression.Execute(&buf, obj.Object); err != nil {
+		return false, fmt.Sprintf("error evaluating jsonpath: %s", err)
+	}
+	result := strings.TrimSpace(buf.String())
+	return result == value, fmt.Sprintf("jsonpath returned %q, expecting %q", result, value)
+}
+
+// allResourcesReady reports whether at least one resource was seen and all of them are ready
+func allResourcesReady(ready map[string]bool) bool {
+	if len(ready) == 0 {
+		return false
+	}
+	for _, r := range ready {
+		if !r {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForResourceDeleted waits for every resource matching the provided name or selector
+// to be removed from the API
+// If resources remain after the timeout, their finalizers and deletion conditions are reported
+func WaitForResourceDeleted(ctx context.Context, restConfig *rest.Config, o *ResourceWaitOptions) error {
+	// Filter
+	selector := ObjectSelector{
+		Name:          o.Name,
+		LabelSelector: o.Selector,
+		FieldSelector: o.FieldSelector,
+	}
+	if err := selector.Validate(); err != nil {
+		return err
+	}
+	listOptions := selector.ListOptions()
+	target := selector.String()
+
+	// Every resource of a kind is removed along with the CustomResourceDefinition serving it
+	resource, err := resourceInterface(ctx, restConfig, o.APIVersion, o.Kind, o.Namespace, false)
+	if meta.IsNoMatchError(err) {
+		log.Infof("%s %s is not served by the API, no %s matching %s exists", o.APIVersion, o.Kind, o.Kind, target)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lw := newListWatch[*unstructured.UnstructuredList](ctx, resource, listOptions)
+
+	// The informer lists first, as a watch does not report anything when nothing matches
+	remaining := make(map[string]*unstructured.Unstructured)
+	var synced bool
+	err = watchUntil(ctx, lw, &unstructured.Unstructured{}, watchOptions{
+		kind: o.Kind,
+		synced: func(store cache.Store) (bool, error) {
+			synced = true
+			if len(remaining) == 0 {
+				log.Infof("no %s matching %s exists", o.Kind, target)
+				return true, nil
+			}
+			log.Infof("waiting for %v %s matching %s to be deleted - this could take up to %v", len(remaining), o.Kind, target, TimeRemaining(ctx))
+			return false, nil
+		},
+	}, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			delete(remaining, obj.GetName())
+			log.Infof("%s %s was deleted", o.Kind, obj.GetName())
+		} else {
+			remaining[obj.GetName()] = obj
+		}
+
+		if synced && len(remaining) == 0 {
+			log.Infof("all %s resources matching %s were deleted", o.Kind, target)
+			return true, nil
+		}
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, ErrWaitTimeout):
+		log.Errorf("the %s was not deleted within the timeout period", o.Kind)
+		var blockers []string
+		for _, obj := range remaining {
+			blockers = append(blockers, fmt.Sprintf("%s %s", obj.GetName(), deletionBlockers(obj)))
+		}
+		return fmt.Errorf("timed out waiting for %s %s to be deleted: %s", o.Kind, target, strings.Join(blockers, "; "))
+	case err != nil:
+		return fmt.Errorf("error waiting for %s %s to be deleted: %s", o.Kind, target, err)
+	}
+	return nil
+}
+
+// deletionBlockers describes what is preventing an object from being deleted:
+// whether deletion was requested, its remaining finalizers and, for Namespaces,
+// the content and finalizers that are still being removed
+func deletionBlockers(obj *unstructured.Unstructured) string {
+	var blockers []string
+	if obj.GetDeletionTimestamp() == nil {
+		blockers = append(blockers, "has not been marked for deletion")
+	}
+	if finalizers := obj.GetFinalizers(); len(finalizers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("finalizers [%s]", strings.Join(finalizers, ", ")))
+	}
+	// Namespace finalizers are set in the spec rather than in the metadata
+	if finalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers"); len(finalizers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("spec finalizers [%s]", strings.Join(finalizers, ", ")))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType := fmt.Sprint(condition["type"])
+		if conditionType != "NamespaceContentRemaining" && conditionType != "NamespaceFinalizersRemaining" {
+			continue
+		}
+		if fmt.Sprint(condition["status"]) == string(metav1.ConditionTrue) {
+			if message := conditionMessage(condition); message != "" {
+				blockers = append(blockers, fmt.Sprintf("%s: %s", conditionType, message))
+			} else {
+				blockers = append(blockers, conditionType)
+			}
+		}
+	}
+
+	if len(blockers) == 0 {
+		return "is being deleted"
+	}
+	return strings.Join(blockers, ", ")
+}
@@ -0,0 +1,206 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testResource() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name": "widget",
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":   "Ready",
+					"status": "True",
+				},
+				map[string]interface{}{
+					"type":    "Degraded",
+					"status":  "False",
+					"reason":  "AsExpected",
+					"message": "all good",
+				},
+			},
+		},
+	}}
+}
+
+func TestParseConditionTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		condition  string
+		wantType   string
+		wantStatus string
+	}{
+		{name: "type only defaults to True", condition: "Ready", wantType: "Ready", wantStatus: "True"},
+		{name: "type and status", condition: "Ready=False", wantType: "Ready", wantStatus: "False"},
+		{name: "empty status defaults to True", condition: "Ready=", wantType: "Ready", wantStatus: "True"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotStatus := ParseConditionTarget(tt.condition)
+			if gotType != tt.wantType || gotStatus != tt.wantStatus {
+				t.Errorf("ParseConditionTarget() = %s, %s, want %s, %s", gotType, gotStatus, tt.wantType, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestResourceConditionMet(t *testing.T) {
+	tests := []struct {
+		name            string
+		conditionType   string
+		conditionStatus string
+		want            bool
+	}{
+		{name: "matching condition", conditionType: "Ready", conditionStatus: "True", want: true},
+		{name: "status is compared case insensitively", conditionType: "Ready", conditionStatus: "true", want: true},
+		{name: "different status", conditionType: "Degraded", conditionStatus: "True", want: false},
+		{name: "missing condition", conditionType: "Available", conditionStatus: "True", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := resourceConditionMet(testResource(), tt.conditionType, tt.conditionStatus); got != tt.want {
+				t.Errorf("resourceConditionMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, detail := resourceConditionMet(testResource(), "Degraded", "False")
+	if want := "condition Degraded is False - AsExpected: all good"; detail != want {
+		t.Errorf("resourceConditionMet() = %s, want %s", detail, want)
+	}
+	// A condition without a message is described by its reason alone
+	widget := testResource()
+	conditions, _, _ := unstructured.NestedSlice(widget.Object, "status", "conditions")
+	delete(conditions[1].(map[string]interface{}), "message")
+	_ = unstructured.SetNestedSlice(widget.Object, conditions, "status", "conditions")
+	_, detail = resourceConditionMet(widget, "Degraded", "False")
+	if want := "condition Degraded is False - AsExpected"; detail != want {
+		t.Errorf("resourceConditionMet() = %s, want %s", detail, want)
+	}
+}
+
+func TestResourceJSONPathMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		value      string
+		want       bool
+	}{
+		{name: "matching value", expression: "{.status.phase}", value: "Running", want: true},
+		{name: "braces are optional", expression: ".status.phase", value: "Running", want: true},
+		{name: "different value", expression: "{.status.phase}", value: "Pending", want: false},
+		{name: "missing field", expression: "{.status.missing}", value: "Running", want: false},
+		{name: "filter expression", expression: `{.status.conditions[?(@.type=="Ready")].status}`, value: "True", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := parseJSONPath(tt.expression)
+			if err != nil {
+				t.Fatalf("parseJSONPath() error = %v", err)
+			}
+			if got, _ := resourceJSONPathMatches(testResource(), expression, tt.value); got != tt.want {
+				t.Errorf("resourceJSONPathMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeletionBlockers(t *testing.T) {
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name":              "argo",
+			"deletionTimestamp": "2023-01-01T00:00:00Z",
+		},
+		"spec": map[string]interface{}{
+			"finalizers": []interface{}{"kubernetes"},
+		},
+		"status": map[string]interface{}{
+			"phase": "Terminating",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":    "NamespaceContentRemaining",
+					"status":  "True",
+					"message": "Some resources are remaining: applications.argoproj.io has 1 resource instances",
+				},
+				map[string]interface{}{
+					"type":    "NamespaceFinalizersRemaining",
+					"status":  "True",
+					"message": "Some content in the namespace has finalizers remaining: resources-finalizer.argocd.argoproj.io in 1 resource instances",
+				},
+				map[string]interface{}{
+					"type":   "NamespaceDeletionDiscoveryFailure",
+					"status": "False",
+				},
+			},
+		},
+	}}
+	want := "spec finalizers [kubernetes], " +
+		"NamespaceContentRemaining: Some resources are remaining: applications.argoproj.io has 1 resource instances, " +
+		"NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: resources-finalizer.argocd.argoproj.io in 1 resource instances"
+	if got := deletionBlockers(namespace); got != want {
+		t.Errorf("deletionBlockers() = %s, want %s", got, want)
+	}
+
+	widget := testResource()
+	widget.SetFinalizers([]string{"example.com/cleanup"})
+	if got, want := deletionBlockers(widget), "has not been marked for deletion, finalizers [example.com/cleanup]"; got != want {
+		t.Errorf("deletionBlockers() = %s, want %s", got, want)
+	}
+}
+
+func TestDiscoverRESTMapping(t *testing.T) {
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	widgets := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+
+	// The kind is not served until its CustomResourceDefinition has been installed
+	if _, err := discoverRESTMapping(discoveryClient, widgets); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected a no match error, got %v", err)
+	}
+
+	discoveryClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	}}
+	mapping, err := discoverRESTMapping(discoveryClient, widgets)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mapping.Resource.Resource != "widgets" || mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		t.Errorf("unexpected mapping %v", mapping.Resource)
+	}
+}
+
+func TestWaitForRESTMappingNotServed(t *testing.T) {
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	widgets := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Deletion waits do not wait for a kind whose CustomResourceDefinition is already gone
+	if _, err := waitForRESTMapping(ctx, discoveryClient, widgets, false); !meta.IsNoMatchError(err) {
+		t.Errorf("expected a no match error, got %v", err)
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if _, err := waitForRESTMapping(shortCtx, discoveryClient, widgets, true); err == nil || meta.IsNoMatchError(err) {
+		t.Errorf("expected a timeout waiting for the kind to be served, got %v", err)
+	}
+}
@@ -68,7 +68,10 @@ func UpdateConfigMapV2(inCluster string, namespace, configMapName string, key st
 		return fmt.Errorf("error getting ConfigMap: %s", err)
 	}
 
-	configMap.Data = map[string]string{key: value}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[key] = value
 	_, err = clientset.CoreV1().ConfigMaps(namespace).Update(
 		context.Background(),
 		configMap,
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ObjectSelector identifies the objects to wait on by name, label selector and/or field selector
+type ObjectSelector struct {
+	Name          string
+	LabelSelector string
+	FieldSelector string
+}
+
+// Validate checks that at least one criteria is provided and that the selectors can be parsed
+func (s ObjectSelector) Validate() error {
+	if s.Name == "" && s.LabelSelector == "" && s.FieldSelector == "" {
+		return fmt.Errorf("please provide a name, a label selector or a field selector")
+	}
+	if _, err := labels.Parse(s.LabelSelector); err != nil {
+		return fmt.Errorf("please check the provided label selector %s: %s", s.LabelSelector, err)
+	}
+	if _, err := fields.ParseSelector(s.FieldSelector); err != nil {
+		return fmt.Errorf("please check the provided field selector %s: %s", s.FieldSelector, err)
+	}
+	return nil
+}
+
+// ListOptions returns the list options used to list or watch the selected objects
+func (s ObjectSelector) ListOptions() metav1.ListOptions {
+	fieldSelectors := []string{}
+	if s.Name != "" {
+		fieldSelectors = append(fieldSelectors, fmt.Sprintf("metadata.name=%s", s.Name))
+	}
+	if s.FieldSelector != "" {
+		fieldSelectors = append(fieldSelectors, s.FieldSelector)
+	}
+	return metav1.ListOptions{
+		LabelSelector: s.LabelSelector,
+		FieldSelector: strings.Join(fieldSelectors, ","),
+	}
+}
+
+// String describes the selected objects for logging
+func (s ObjectSelector) String() string {
+	var parts []string
+	if s.Name != "" {
+		parts = append(parts, fmt.Sprintf("name %s", s.Name))
+	}
+	if s.LabelSelector != "" {
+		parts = append(parts, fmt.Sprintf("labels %s", s.LabelSelector))
+	}
+	if s.FieldSelector != "" {
+		parts = append(parts, fmt.Sprintf("fields %s", s.FieldSelector))
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,44 @@
+package kubernetes
+
+import "testing"
+
+func TestObjectSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector ObjectSelector
+		wantErr  bool
+	}{
+		{name: "name", selector: ObjectSelector{Name: "vault"}},
+		{name: "equality label", selector: ObjectSelector{LabelSelector: "app=vault"}},
+		{
+			name:     "set based label",
+			selector: ObjectSelector{LabelSelector: "app.kubernetes.io/name=vault,app.kubernetes.io/component in (server, agent),tier!=cache,!legacy"},
+		},
+		{name: "field selector", selector: ObjectSelector{FieldSelector: "status.phase=Running"}},
+		{name: "empty", selector: ObjectSelector{}, wantErr: true},
+		{name: "invalid label", selector: ObjectSelector{LabelSelector: "app in vault"}, wantErr: true},
+		{name: "invalid field", selector: ObjectSelector{FieldSelector: "status.phase"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.selector.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectSelectorListOptions(t *testing.T) {
+	selector := ObjectSelector{
+		Name:          "vault-0",
+		LabelSelector: "app=vault,tier notin (cache)",
+		FieldSelector: "status.phase=Running",
+	}
+	got := selector.ListOptions()
+	if got.LabelSelector != "app=vault,tier notin (cache)" {
+		t.Errorf("ListOptions() LabelSelector = %s", got.LabelSelector)
+	}
+	if got.FieldSelector != "metadata.name=vault-0,status.phase=Running" {
+		t.Errorf("ListOptions() FieldSelector = %s", got.FieldSelector)
+	}
+}
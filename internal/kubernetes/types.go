@@ -23,3 +23,26 @@ type CreateK8sSecretCmdOptions struct {
 	Name                string
 	KubeInClusterConfig string
 }
+
+// ResourceWaitOptions describes a generic resource to wait on using the dynamic client
+type ResourceWaitOptions struct {
+	APIVersion    string
+	Kind          string
+	Namespace     string
+	Name          string
+	Selector      string
+	FieldSelector string
+	Condition     string
+	JSONPath      string
+	JSONPathValue string
+}
+
+// KeyWaitOptions describes a Secret or ConfigMap key to wait on. Without Value or ValueRegex,
+// the key must be present with a non-empty value
+type KeyWaitOptions struct {
+	Namespace  string
+	Name       string
+	Key        string
+	Value      string
+	ValueRegex string
+}
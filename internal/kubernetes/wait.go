@@ -0,0 +1,228 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+const (
+	// pollInterval is the time between polls while a condition is not met yet
+	pollInterval = 2 * time.Second
+	// maxPollBackoff caps the time between polls after transient errors
+	maxPollBackoff = 30 * time.Second
+)
+
+// ErrWaitTimeout is returned by the wait engine once the deadline of its context has passed
+var ErrWaitTimeout = errors.New("timed out waiting for the condition")
+
+// listWatchClient is implemented by the typed and dynamic clients of every resource
+type listWatchClient[T runtime.Object] interface {
+	List(ctx context.Context, opts metav1.ListOptions) (T, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// newListWatch returns a ListerWatcher for the objects of client matching the selectors of listOptions
+func newListWatch[T runtime.Object](ctx context.Context, client listWatchClient[T], listOptions metav1.ListOptions) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = listOptions.LabelSelector
+			options.FieldSelector = listOptions.FieldSelector
+			return client.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = listOptions.LabelSelector
+			options.FieldSelector = listOptions.FieldSelector
+			return client.Watch(ctx, options)
+		},
+	}
+}
+
+// nameListOptions returns list options matching a single object by name
+func nameListOptions(name string) metav1.ListOptions {
+	return metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("metadata.name=%s", name),
+	}
+}
+
+// watchOptions configures watchUntil
+type watchOptions struct {
+	// kind names the watched objects in log messages, it defaults to the type of the objects,
+	// which does not identify the resource for unstructured objects
+	kind string
+	// resync delivers every known object again as a Modified event at this interval, for
+	// conditions that also depend on objects that are not watched
+	resync time.Duration
+	// synced is called with the current objects once the initial list has been delivered
+	synced func(store cache.Store) (bool, error)
+}
+
+// watchUntil runs an informer for the objects returned by lw and calls condition with every event
+// until it reports done or returns an error
+// The informer relists and rewatches with backoff when the watch expires, the API server restarts
+// or the API is not served yet, so only permanent errors or the deadline of ctx end the wait early
+func watchUntil(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, o watchOptions, condition watchtools.ConditionFunc) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Stop on errors that retrying will not resolve rather than waiting for the deadline
+	kind := o.kind
+	if kind == "" {
+		kind = reflect.TypeOf(objType).Elem().Name()
+	}
+	guarded := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			obj, err := lw.List(options)
+			if err != nil && ctx.Err() == nil {
+				if permanentError(err) {
+					cancel(err)
+				} else {
+					log.Warnf("error listing %s, retrying: %s", kind, err)
+				}
+			}
+			return obj, err
+		},
+		WatchFunc: lw.Watch,
+	}
+
+	events := make(chan watch.Event)
+	send := func(eventType watch.EventType, obj interface{}) {
+		if stale, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = stale.Obj
+		}
+		object, ok := obj.(runtime.Object)
+		if !ok {
+			return
+		}
+		select {
+		case events <- watch.Event{Type: eventType, Object: object}:
+		case <-ctx.Done():
+		}
+	}
+	store, controller := cache.NewInformer(guarded, objType, o.resync, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { send(watch.Added, obj) },
+		UpdateFunc: func(_, obj interface{}) { send(watch.Modified, obj) },
+		DeleteFunc: func(obj interface{}) { send(watch.Deleted, obj) },
+	})
+	go controller.Run(ctx.Done())
+
+	// Events are delivered before the informer reports it has synced, so the synced callback
+	// always sees every object of the initial list
+	synced := make(chan struct{})
+	go func() {
+		if cache.WaitForCacheSync(ctx.Done(), controller.HasSynced) {
+			close(synced)
+		}
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			done, err := condition(event)
+			if err != nil || done {
+				return err
+			}
+		case <-synced:
+			synced = nil
+			if o.synced == nil {
+				continue
+			}
+			done, err := o.synced(store)
+			if err != nil || done {
+				return err
+			}
+		case <-ctx.Done():
+			return contextError(ctx)
+		}
+	}
+}
+
+// pollUntil calls an API condition until it reports done, returns a permanent error or ctx is done
+func pollUntil(ctx context.Context, condition func(ctx context.Context) (bool, error)) error {
+	return PollUntil(ctx, pollInterval, transientError, condition)
+}
+
+// PollUntil calls condition every interval until it reports done, returns an error or ctx is
+// done, in which case ErrWaitTimeout is returned once its deadline has passed
+// Errors accepted by retryable are logged and retried with an exponential backoff, every other
+// error ends the wait, retryable may be nil when no error should be retried
+func PollUntil(ctx context.Context, interval time.Duration, retryable func(err error) bool, condition func(ctx context.Context) (bool, error)) error {
+	backoff := wait.Backoff{
+		Duration: interval,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    10,
+		Cap:      maxPollBackoff,
+	}
+	if interval > maxPollBackoff {
+		backoff.Cap = interval
+	}
+	for {
+		done, err := condition(ctx)
+		switch {
+		case err != nil && (retryable == nil || !retryable(err)):
+			return err
+		case err != nil:
+			log.Warnf("retrying after error: %s", err)
+		case done:
+			return nil
+		}
+
+		next := interval
+		if err != nil {
+			next = backoff.Step()
+		} else {
+			backoff.Duration = interval
+			backoff.Steps = 10
+		}
+		select {
+		case <-time.After(next):
+		case <-ctx.Done():
+			return contextError(ctx)
+		}
+	}
+}
+
+// contextError returns ErrWaitTimeout when the deadline of ctx has passed, or why it was canceled
+func contextError(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return ErrWaitTimeout
+	}
+	return cause
+}
+
+// transientError reports whether an API error may be resolved by retrying
+func transientError(err error) bool {
+	return !permanentError(err)
+}
+
+// permanentError reports whether an API error will not be resolved by retrying
+// Timeouts, throttling, connection failures and APIs that are not served yet are all retried
+func permanentError(err error) bool {
+	return apierrors.IsForbidden(err) ||
+		apierrors.IsUnauthorized(err) ||
+		apierrors.IsBadRequest(err) ||
+		apierrors.IsInvalid(err) ||
+		apierrors.IsMethodNotSupported(err)
+}
+
+// TimeRemaining returns the time left until the deadline of ctx, rounded to the second
+func TimeRemaining(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline).Round(time.Second)
+}
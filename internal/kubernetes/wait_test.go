@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     appsv1.DeploymentStatus{Replicas: replicas},
+	}
+}
+
+func TestWatchUntil(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = clientset.AppsV1().Deployments("default").Create(ctx, testDeployment("app", 1), metav1.CreateOptions{})
+	}()
+
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	err := watchUntil(ctx, lw, &appsv1.Deployment{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		deployment, ok := event.Object.(*appsv1.Deployment)
+		return ok && deployment.Name == "app", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestWatchUntilTimeout(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testDeployment("app", 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	err := watchUntil(ctx, lw, &appsv1.Deployment{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestWatchUntilPermanentError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "", errors.New("denied"))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	err := watchUntil(ctx, lw, &appsv1.Deployment{}, watchOptions{}, func(event watch.Event) (bool, error) {
+		return false, nil
+	})
+	if !apierrors.IsForbidden(err) {
+		t.Fatalf("expected a forbidden error, got %v", err)
+	}
+}
+
+func TestWatchUntilTransientError(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testDeployment("app", 1))
+	var failures int
+	clientset.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures < 1 {
+			failures++
+			return true, nil, apierrors.NewServiceUnavailable("the server is restarting")
+		}
+		return false, nil, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	// The kind names the resource in the retry warning instead of the Go type
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	err := watchUntil(ctx, lw, &appsv1.Deployment{}, watchOptions{kind: "Widget"}, func(event watch.Event) (bool, error) {
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the list to be retried, got %s", err)
+	}
+	var warned bool
+	for _, entry := range hook.AllEntries() {
+		warned = warned || strings.HasPrefix(entry.Message, "error listing Widget, retrying")
+	}
+	if !warned {
+		t.Errorf("expected a retry warning naming the Widget kind")
+	}
+}
+
+func TestWaitForObjectCount(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testDeployment("c", 1), testDeployment("a", 1), testDeployment("b", 1))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	objects, err := waitForObjectCount(ctx, lw, &appsv1.Deployment{}, "Deployment", ObjectSelector{LabelSelector: "app=test"}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Every existing object is returned rather than only the first minCount of them
+	var names []string
+	for _, obj := range objects {
+		names = append(names, objectName(obj))
+	}
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("expected [a b c], got %v", names)
+	}
+}
+
+func TestWaitForObjectCountReturnsUnreconciledStatefulSets(t *testing.T) {
+	// A new StatefulSet has an empty status until the controller reconciles it, it is still
+	// returned as statefulSetReady waits for its spec to be observed
+	created := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+	}
+	reconciled := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 1},
+	}
+	clientset := fake.NewSimpleClientset(created, reconciled)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lw := newListWatch[*appsv1.StatefulSetList](ctx, clientset.AppsV1().StatefulSets("default"), metav1.ListOptions{})
+	objects, err := waitForObjectCount(ctx, lw, &appsv1.StatefulSet{}, "StatefulSet", ObjectSelector{LabelSelector: "app=test"}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(objects) != 2 || objectName(objects[0]) != "a" || objectName(objects[1]) != "b" {
+		t.Fatalf("expected the StatefulSets a and b, got %v objects", len(objects))
+	}
+	if ready, _ := statefulSetReady(objects[0].(*appsv1.StatefulSet)); ready {
+		t.Errorf("expected the unreconciled StatefulSet a not to be ready")
+	}
+}
+
+func TestWaitForObject(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testDeployment("b", 1), testDeployment("a", 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lw := newListWatch[*appsv1.DeploymentList](ctx, clientset.AppsV1().Deployments("default"), metav1.ListOptions{})
+	obj, err := waitForObject(ctx, lw, &appsv1.Deployment{}, "Deployment", ObjectSelector{LabelSelector: "app=test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if name := objectName(obj); name != "a" {
+		t.Errorf("expected the first Deployment by name to be returned, got %s", name)
+	}
+}
+
+func TestPollUntil(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "things"}, "", errors.New("denied"))
+	if err := pollUntil(ctx, func(ctx context.Context) (bool, error) { return false, forbidden }); !apierrors.IsForbidden(err) {
+		t.Errorf("expected the permanent error to be returned, got %v", err)
+	}
+	if err := pollUntil(ctx, func(ctx context.Context) (bool, error) { return true, nil }); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if err := pollUntil(shortCtx, func(ctx context.Context) (bool, error) { return false, nil }); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// lifecycleRuleID identifies the lifecycle rule managed by EnsureBuckets, other rules are kept
+const lifecycleRuleID = "kubernetes-toolkit-expiration"
+
+// LoadBucketsSpec reads and validates a buckets spec file
+func LoadBucketsSpec(fs afero.Fs, path string) (*BucketsSpec, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading buckets spec file %s: %s", path, err)
+	}
+
+	var spec BucketsSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("error parsing buckets spec file %s: %s", path, err)
+	}
+
+	if len(spec.Buckets) == 0 {
+		return nil, fmt.Errorf("the buckets spec does not contain any buckets")
+	}
+	for _, bucket := range spec.Buckets {
+		if err := bucket.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &spec, nil
+}
+
+// validate checks that the settings of a bucket can be applied together
+func (b BucketSpec) validate() error {
+	switch {
+	case b.Name == "":
+		return fmt.Errorf("every bucket requires a name")
+	case b.ObjectLock && b.Versioning != nil && !*b.Versioning:
+		return fmt.Errorf("bucket %s: object lock requires versioning", b.Name)
+	case b.Lifecycle != nil && b.Lifecycle.ExpirationDays == 0 && b.Lifecycle.NoncurrentExpirationDays == 0:
+		return fmt.Errorf("bucket %s: lifecycle requires expirationDays or noncurrentExpirationDays", b.Name)
+	case b.Policy != "" && !json.Valid([]byte(b.Policy)):
+		return fmt.Errorf("bucket %s: policy is not valid JSON", b.Name)
+	}
+	return nil
+}
+
+// EnsureBuckets creates the missing buckets and applies their settings, returning the changes
+// that were made, or that would be made when dryRun is set
+func EnsureBuckets(minioClient *minio.Client, spec *BucketsSpec, region string, dryRun bool) ([]BucketChange, error) {
+	var changes []BucketChange
+	for _, bucket := range spec.Buckets {
+		bucketChanges, err := ensureBucket(minioClient, bucket, region, dryRun)
+		for _, change := range bucketChanges {
+			changes = append(changes, BucketChange{Bucket: bucket.Name, Change: change})
+		}
+		if err != nil {
+			return changes, fmt.Errorf("error ensuring bucket %s: %s", bucket.Name, err)
+		}
+	}
+	return changes, nil
+}
+
+// ensureBucket creates a single bucket if it is missing and applies its settings
+func ensureBucket(minioClient *minio.Client, bucket BucketSpec, region string, dryRun bool) ([]string, error) {
+	ctx := context.Background()
+	var changes []string
+	apply := func(change string, f func() error) error {
+		changes = append(changes, change)
+		if dryRun {
+			return nil
+		}
+		log.Infof("bucket %s: %s", bucket.Name, change)
+		return f()
+	}
+
+	exists, err := minioClient.BucketExists(ctx, bucket.Name)
+	if err != nil {
+		return changes, fmt.Errorf("error checking bucket existence: %s", err)
+	}
+	if !exists {
+		change := "created"
+		if bucket.ObjectLock {
+			change = "created with object lock"
+		}
+		err := apply(change, func() error {
+			return minioClient.MakeBucket(ctx, bucket.Name, minio.MakeBucketOptions{Region: region, ObjectLocking: bucket.ObjectLock})
+		})
+		if err != nil || dryRun {
+			// The settings of a bucket that does not exist yet cannot be compared
+			return append(changes, pendingSettings(bucket)...), err
+		}
+	} else if bucket.ObjectLock {
+		objectLock, _, _, _, err := minioClient.GetObjectLockConfig(ctx, bucket.Name)
+		if err != nil && minio.ToErrorResponse(err).Code != "ObjectLockConfigurationNotFoundError" {
+			return changes, fmt.Errorf("error reading object lock configuration: %s", err)
+		}
+		if objectLock != "Enabled" {
+			return changes, fmt.Errorf("object lock can only be enabled when a bucket is created")
+		}
+	}
+
+	if bucket.Versioning != nil {
+		versioning, err := minioClient.GetBucketVersioning(ctx, bucket.Name)
+		if err != nil {
+			return changes, fmt.Errorf("error reading versioning configuration: %s", err)
+		}
+		switch {
+		case *bucket.Versioning && !versioning.Enabled():
+			err = apply("enabled versioning", func() error { return minioClient.EnableVersioning(ctx, bucket.Name) })
+		case !*bucket.Versioning && versioning.Enabled():
+			err = apply("suspended versioning", func() error { return minioClient.SuspendVersioning(ctx, bucket.Name) })
+		}
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	if bucket.Lifecycle != nil {
+		current, err := minioClient.GetBucketLifecycle(ctx, bucket.Name)
+		if err != nil {
+			if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
+				return changes, fmt.Errorf("error reading lifecycle configuration: %s", err)
+			}
+			current = lifecycle.NewConfiguration()
+		}
+		if desired, changed := lifecycleConfiguration(current, bucket.Lifecycle); changed {
+			err := apply("applied lifecycle expiration", func() error { return minioClient.SetBucketLifecycle(ctx, bucket.Name, desired) })
+			if err != nil {
+				return changes, err
+			}
+		}
+	}
+
+	if bucket.Policy != "" {
+		current, err := minioClient.GetBucketPolicy(ctx, bucket.Name)
+		if err != nil {
+			return changes, fmt.Errorf("error reading bucket policy: %s", err)
+		}
+		if !equalPolicy(current, bucket.Policy) {
+			err := apply("applied bucket policy", func() error { return minioClient.SetBucketPolicy(ctx, bucket.Name, bucket.Policy) })
+			if err != nil {
+				return changes, err
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// pendingSettings describes the settings that will be applied to a bucket once it is created
+func pendingSettings(bucket BucketSpec) []string {
+	var changes []string
+	if bucket.Versioning != nil && *bucket.Versioning && !bucket.ObjectLock {
+		changes = append(changes, "enabled versioning")
+	}
+	if bucket.Lifecycle != nil {
+		changes = append(changes, "applied lifecycle expiration")
+	}
+	if bucket.Policy != "" {
+		changes = append(changes, "applied bucket policy")
+	}
+	return changes
+}
+
+// lifecycleConfiguration returns the lifecycle configuration with the managed expiration rule
+// set to the spec, and whether it differs from the current configuration
+func lifecycleConfiguration(current *lifecycle.Configuration, spec *LifecycleSpec) (*lifecycle.Configuration, bool) {
+	rule := lifecycle.Rule{
+		ID:     lifecycleRuleID,
+		Status: "Enabled",
+		RuleFilter: lifecycle.Filter{
+			Prefix: spec.Prefix,
+		},
+		Expiration: lifecycle.Expiration{
+			Days: lifecycle.ExpirationDays(spec.ExpirationDays),
+		},
+		NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
+			NoncurrentDays: lifecycle.ExpirationDays(spec.NoncurrentExpirationDays),
+		},
+	}
+
+	desired := lifecycle.NewConfiguration()
+	changed := true
+	for _, existing := range current.Rules {
+		if existing.ID != lifecycleRuleID {
+			desired.Rules = append(desired.Rules, existing)
+			continue
+		}
+		changed = !lifecycleRuleEqual(existing, rule)
+	}
+	desired.Rules = append(desired.Rules, rule)
+	return desired, changed
+}
+
+// lifecycleRuleEqual compares the settings of a managed lifecycle rule
+func lifecycleRuleEqual(a lifecycle.Rule, b lifecycle.Rule) bool {
+	return a.Status == b.Status &&
+		a.RuleFilter.Prefix == b.RuleFilter.Prefix &&
+		a.Expiration.Days == b.Expiration.Days &&
+		a.NoncurrentVersionExpiration.NoncurrentDays == b.NoncurrentVersionExpiration.NoncurrentDays
+}
+
+// equalPolicy reports whether two bucket policy documents are equal, ignoring formatting
+// and the normalization minio applies when storing a policy: a single value and an array
+// holding only that value are equal, the order of string arrays is ignored and the "*"
+// principal is the same as {"AWS": "*"}
+func equalPolicy(a string, b string) bool {
+	var aValue, bValue interface{}
+	if json.Unmarshal([]byte(a), &aValue) != nil || json.Unmarshal([]byte(b), &bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalizePolicy(aValue), normalizePolicy(bValue))
+}
+
+// normalizePolicy replaces every array holding a single value by that value, sorts arrays
+// of strings and expands the "*" principal shorthand
+func normalizePolicy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			item = normalizePolicy(item)
+			if (key == "Principal" || key == "NotPrincipal") && item == "*" {
+				item = map[string]interface{}{"AWS": "*"}
+			}
+			v[key] = item
+		}
+		return v
+	case []interface{}:
+		if len(v) == 1 {
+			return normalizePolicy(v[0])
+		}
+		strs := make([]string, 0, len(v))
+		for i, item := range v {
+			v[i] = normalizePolicy(item)
+			if str, ok := v[i].(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		if len(strs) < len(v) {
+			return v
+		}
+		sort.Strings(strs)
+		for i, str := range strs {
+			v[i] = str
+		}
+		return v
+	}
+	return value
+}
+
+// PrintChanges writes the changes made to the buckets as a table
+func PrintChanges(w io.Writer, changes []BucketChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "all buckets are up to date")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUCKET\tCHANGE")
+	for _, change := range changes {
+		fmt.Fprintf(tw, "%s\t%s\n", change.Bucket, change.Change)
+	}
+	tw.Flush()
+}
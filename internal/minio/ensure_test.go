@@ -0,0 +1,108 @@
+package minio
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/spf13/afero"
+)
+
+func TestLoadBucketsSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{
+			name: "valid spec",
+			spec: `buckets:
+  - name: kubefirst-state-store
+    versioning: true
+    objectLock: true
+    lifecycle:
+      noncurrentExpirationDays: 30
+  - name: argo-artifacts
+    policy: '{"Version":"2012-10-17","Statement":[]}'
+`,
+		},
+		{name: "no buckets", spec: "buckets: []", wantErr: true},
+		{name: "object lock without versioning", spec: "buckets:\n  - name: state\n    versioning: false\n    objectLock: true\n", wantErr: true},
+		{name: "empty lifecycle", spec: "buckets:\n  - name: state\n    lifecycle:\n      prefix: tmp/\n", wantErr: true},
+		{name: "invalid policy", spec: "buckets:\n  - name: state\n    policy: '{'\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if err := afero.WriteFile(fs, "buckets.yaml", []byte(tt.spec), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadBucketsSpec(fs, "buckets.yaml")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadBucketsSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLifecycleConfiguration(t *testing.T) {
+	spec := &LifecycleSpec{Prefix: "logs/", ExpirationDays: 7}
+	otherRule := lifecycle.Rule{ID: "other", Status: "Enabled", Expiration: lifecycle.Expiration{Days: 1}}
+
+	current := lifecycle.NewConfiguration()
+	current.Rules = []lifecycle.Rule{otherRule}
+	desired, changed := lifecycleConfiguration(current, spec)
+	if !changed {
+		t.Errorf("lifecycleConfiguration() changed = false for a missing rule")
+	}
+	if len(desired.Rules) != 2 || desired.Rules[0].ID != "other" || desired.Rules[1].ID != lifecycleRuleID {
+		t.Errorf("lifecycleConfiguration() rules = %+v, want the other rule and the managed rule", desired.Rules)
+	}
+
+	if _, changed := lifecycleConfiguration(desired, spec); changed {
+		t.Errorf("lifecycleConfiguration() changed = true for an applied rule")
+	}
+	if _, changed := lifecycleConfiguration(desired, &LifecycleSpec{Prefix: "logs/", ExpirationDays: 14}); !changed {
+		t.Errorf("lifecycleConfiguration() changed = false for a different expiration")
+	}
+}
+
+func TestEqualPolicy(t *testing.T) {
+	// minio stores a policy with single values as arrays and the "*" principal expanded
+	stored := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::public","arn:aws:s3:::public/*"]}]}`
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "formatting", a: `{"Version": "2012-10-17", "Statement": []}`, b: `{"Statement":[],"Version":"2012-10-17"}`, want: true},
+		{name: "missing policy", a: "", b: `{"Version":"2012-10-17"}`, want: false},
+		{
+			name: "scalar and single item array",
+			a:    `{"Statement":[{"Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::public/*"]}]}`,
+			b:    `{"Statement":[{"Principal":{"AWS":"*"},"Action":"s3:GetObject","Resource":"arn:aws:s3:::public/*"}]}`,
+			want: true,
+		},
+		{
+			name: "principal shorthand",
+			a:    stored,
+			b:    `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::public","arn:aws:s3:::public/*"]}]}`,
+			want: true,
+		},
+		{
+			name: "reordered actions and resources",
+			a:    stored,
+			b:    `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":["s3:ListBucket","s3:GetObject"],"Resource":["arn:aws:s3:::public/*","arn:aws:s3:::public"]}]}`,
+			want: true,
+		},
+		{name: "different actions", a: `{"Action":["s3:GetObject","s3:PutObject"]}`, b: `{"Action":"s3:GetObject"}`, want: false},
+		{name: "different principal", a: `{"Principal":{"AWS":["arn:aws:iam::123:root"]}}`, b: `{"Principal":"*"}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalPolicy(tt.a, tt.b); got != tt.want {
+				t.Errorf("equalPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
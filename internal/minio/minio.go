@@ -0,0 +1,194 @@
+package minio
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultEndpoint     = "minio.minio.svc.cluster.local:9000"
+	DefaultRegion       = "us-k3d-1"
+	DefaultAccessKeyKey = "accesskey"
+	DefaultSecretKeyKey = "secretkey"
+	DefaultAccessKeyEnv = "AWS_ACCESS_KEY_ID"
+	DefaultSecretKeyEnv = "AWS_SECRET_ACCESS_KEY"
+)
+
+// pollInterval is the time between two checks while waiting on minio
+const pollInterval = 5 * time.Second
+
+// DefaultBuckets are the buckets created by the kubefirst platform
+var DefaultBuckets = []string{"chartmuseum", "argo-artifacts", "gitlab-backup", "kubefirst-state-store", "vault-backend"}
+
+// DefaultConfig returns the configuration of the kubefirst platform MinIO, without credentials
+func DefaultConfig() *Config {
+	return &Config{
+		Endpoint: DefaultEndpoint,
+		Region:   DefaultRegion,
+		Buckets:  append([]string{}, DefaultBuckets...),
+		Credentials: Credentials{
+			AccessKeyKey: DefaultAccessKeyKey,
+			SecretKeyKey: DefaultSecretKeyKey,
+			AccessKeyEnv: DefaultAccessKeyEnv,
+			SecretKeyEnv: DefaultSecretKeyEnv,
+		},
+	}
+}
+
+// LoadConfig reads a config file on top of the default configuration
+func LoadConfig(fs afero.Fs, path string) (*Config, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading minio config file %s: %s", path, err)
+	}
+
+	c := DefaultConfig()
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("error parsing minio config file %s: %s", path, err)
+	}
+	return c, nil
+}
+
+// NewClient instantiates a minio client, reading credentials from a Secret or from the environment
+func NewClient(c *Config, inCluster string) (*minio.Client, error) {
+	accessKey, secretKey, err := c.Credentials.resolve(inCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := minio.DefaultTransport(c.Secure)
+	if err != nil {
+		return nil, fmt.Errorf("error creating minio transport: %s", err)
+	}
+	if c.Secure {
+		transport.TLSClientConfig, err = c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	minioClient, err := minio.New(c.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:    c.Secure,
+		Region:    c.Region,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating minio client: %s", err)
+	}
+	return minioClient, nil
+}
+
+// tlsConfig returns the TLS configuration used to connect to the endpoint
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		caBundle, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("the CA file %s does not contain any PEM encoded certificate", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// resolve returns the access key and secret key
+func (c Credentials) resolve(inCluster string) (string, string, error) {
+	if c.SecretName != "" {
+		data, err := kubernetes.ReadSecretV2(inCluster, c.SecretNamespace, c.SecretName)
+		if err != nil {
+			return "", "", err
+		}
+		if data[c.AccessKeyKey] == "" || data[c.SecretKeyKey] == "" {
+			return "", "", fmt.Errorf("secret %s must contain the keys %s and %s", c.SecretName, c.AccessKeyKey, c.SecretKeyKey)
+		}
+		return data[c.AccessKeyKey], data[c.SecretKeyKey], nil
+	}
+
+	accessKey, secretKey := os.Getenv(c.AccessKeyEnv), os.Getenv(c.SecretKeyEnv)
+	if accessKey == "" || secretKey == "" {
+		return "", "", fmt.Errorf("please provide minio credentials through a Secret or the %s and %s environment variables", c.AccessKeyEnv, c.SecretKeyEnv)
+	}
+	return accessKey, secretKey, nil
+}
+
+// WaitForBuckets waits until all of the provided buckets exist or ctx is done, retrying while
+// minio cannot be reached or is not ready to serve requests
+func WaitForBuckets(ctx context.Context, minioClient *minio.Client, buckets []string) error {
+	log.Infof("waiting for minio buckets %s - this could take up to %v", strings.Join(buckets, ", "), kubernetes.TimeRemaining(ctx))
+
+	var missing []string
+	var lastErr error
+	err := kubernetes.PollUntil(ctx, pollInterval, nil, func(ctx context.Context) (bool, error) {
+		missing = nil
+		var checkErr error
+		for _, bucket := range buckets {
+			found, err := minioClient.BucketExists(ctx, bucket)
+			if err != nil {
+				if !retryableError(err) {
+					return false, fmt.Errorf("error checking bucket existence: %s", err)
+				}
+				checkErr = err
+			}
+			if !found {
+				missing = append(missing, bucket)
+			}
+		}
+		if len(missing) == 0 {
+			return true, nil
+		}
+		// Keep the last error from before the deadline rather than the cancellation
+		if checkErr != nil && ctx.Err() == nil {
+			lastErr = checkErr
+			log.Warnf("error checking minio buckets, retrying: %s", checkErr)
+		}
+		log.Infof("waiting for minio buckets to exist, still missing: %s", strings.Join(missing, ", "))
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, kubernetes.ErrWaitTimeout) && lastErr != nil:
+		return fmt.Errorf("timed out waiting for minio buckets, still missing: %s, last error: %s", strings.Join(missing, ", "), lastErr)
+	case errors.Is(err, kubernetes.ErrWaitTimeout):
+		return fmt.Errorf("timed out waiting for minio buckets, still missing: %s", strings.Join(missing, ", "))
+	case err != nil:
+		return err
+	}
+
+	log.Info("all minio buckets created")
+	return nil
+}
+
+// retryableError reports whether a minio error may be resolved by retrying, which is the case
+// for network errors and for server errors returned while minio starts
+func retryableError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return minio.ToErrorResponse(err).StatusCode >= http.StatusInternalServerError
+}
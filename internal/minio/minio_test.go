@@ -0,0 +1,141 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/spf13/afero"
+)
+
+func TestLoadConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	config := `endpoint: s3.example.com
+secure: true
+buckets:
+  - state
+credentials:
+  secretNamespace: minio
+  secretName: minio-credentials
+`
+	if err := afero.WriteFile(fs, "minio.yaml", []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(fs, "minio.yaml")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := &Config{
+		Endpoint: "s3.example.com",
+		Region:   DefaultRegion,
+		Secure:   true,
+		Buckets:  []string{"state"},
+		Credentials: Credentials{
+			SecretNamespace: "minio",
+			SecretName:      "minio-credentials",
+			AccessKeyKey:    DefaultAccessKeyKey,
+			SecretKeyKey:    DefaultSecretKeyKey,
+			AccessKeyEnv:    DefaultAccessKeyEnv,
+			SecretKeyEnv:    DefaultSecretKeyEnv,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", got, want)
+	}
+
+	if DefaultBuckets[0] != "chartmuseum" {
+		t.Errorf("LoadConfig() modified the default buckets: %v", DefaultBuckets)
+	}
+
+	if err := afero.WriteFile(fs, "unknown.yaml", []byte("endpont: s3.example.com"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(fs, "unknown.yaml"); err == nil {
+		t.Errorf("LoadConfig() expected an error for an unknown field")
+	}
+}
+
+func TestObjectMatches(t *testing.T) {
+	lastModified := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	info := minio.ObjectInfo{Key: "terraform.tfstate", Size: 2048, LastModified: lastModified}
+	tests := []struct {
+		name      string
+		minSize   int64
+		newerThan time.Time
+		want      bool
+	}{
+		{name: "no criteria", want: true},
+		{name: "large enough", minSize: 1024, want: true},
+		{name: "too small", minSize: 4096, want: false},
+		{name: "newer", newerThan: lastModified.Add(-time.Hour), want: true},
+		{name: "older", newerThan: lastModified.Add(time.Hour), want: false},
+		{name: "same time is not newer", newerThan: lastModified, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := objectMatches(info, tt.minSize, tt.newerThan); got != tt.want {
+				t.Errorf("objectMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Head", URL: "http://minio.minio.svc.cluster.local:9000/k1-state-store/", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			want: true,
+		},
+		{
+			name: "server starting",
+			err:  minio.ErrorResponse{StatusCode: http.StatusServiceUnavailable, Code: "XMinioServerNotInitialized"},
+			want: true,
+		},
+		{
+			name: "access denied",
+			err:  minio.ErrorResponse{StatusCode: http.StatusForbidden, Code: "AccessDenied"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableError(tt.err); got != tt.want {
+				t.Errorf("retryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForObjectRetriesUnreachableMinio(t *testing.T) {
+	// Nothing listens on the address once the listener is closed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := listener.Addr().String()
+	listener.Close()
+
+	minioClient, err := minio.New(endpoint, &minio.Options{Creds: credentials.NewStaticV4("access", "secret", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = WaitForObject(ctx, minioClient, &ObjectWaitOptions{Bucket: "k1-state-store", Key: "terraform.tfstate"})
+	if err == nil || !strings.HasPrefix(err.Error(), "timed out waiting for object k1-state-store/terraform.tfstate: error reading object") {
+		t.Errorf("expected a timeout reporting the last error, got %v", err)
+	}
+}
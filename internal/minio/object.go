@@ -0,0 +1,111 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// WaitForObject waits until the object key, or any object under the prefix, exists
+// with at least the minimum size and was modified after the newer than time, or ctx is done
+func WaitForObject(ctx context.Context, minioClient *minio.Client, o *ObjectWaitOptions) error {
+	description := fmt.Sprintf("object %s/%s", o.Bucket, o.Key)
+	if o.Key == "" {
+		description = fmt.Sprintf("an object under %s/%s", o.Bucket, o.Prefix)
+	}
+	log.Infof("waiting for %s - this could take up to %v", description, kubernetes.TimeRemaining(ctx))
+
+	var lastCondition string
+	err := kubernetes.PollUntil(ctx, pollInterval, nil, func(ctx context.Context) (bool, error) {
+		found, condition, err := findObject(ctx, minioClient, o)
+		if err != nil || found {
+			return found, err
+		}
+		// Keep the last condition from before the deadline rather than the cancellation
+		if ctx.Err() == nil {
+			lastCondition = condition
+		}
+		log.Infof("waiting for %s: %s", description, condition)
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, kubernetes.ErrWaitTimeout):
+		return fmt.Errorf("timed out waiting for %s: %s", description, lastCondition)
+	case err != nil:
+		return err
+	}
+	log.Infof("%s exists", description)
+	return nil
+}
+
+// ParseNewerThan parses an RFC3339 timestamp, or a duration before now such as 1h
+func ParseNewerThan(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	newerThan, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("an RFC3339 timestamp or a duration such as 1h, got %q", value)
+	}
+	return newerThan, nil
+}
+
+// findObject reports whether a matching object exists, along with the reason when it does not
+// Errors that may be resolved by retrying, such as minio not being reachable yet, are
+// reported as the reason
+func findObject(ctx context.Context, minioClient *minio.Client, o *ObjectWaitOptions) (bool, string, error) {
+	if o.Key != "" {
+		info, err := minioClient.StatObject(ctx, o.Bucket, o.Key, minio.StatObjectOptions{})
+		if err != nil {
+			switch {
+			case minio.ToErrorResponse(err).Code == "NoSuchKey", minio.ToErrorResponse(err).Code == "NoSuchBucket":
+				return false, err.Error(), nil
+			case retryableError(err):
+				return false, fmt.Sprintf("error reading object %s: %s", o.Key, err), nil
+			}
+			return false, "", fmt.Errorf("error reading object %s: %s", o.Key, err)
+		}
+		matched, reason := objectMatches(info, o.MinSize, o.NewerThan)
+		return matched, reason, nil
+	}
+
+	// Cancelling the context stops the listing when returning early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lastCondition := "no object was found"
+	for info := range minioClient.ListObjects(ctx, o.Bucket, minio.ListObjectsOptions{Prefix: o.Prefix, Recursive: true}) {
+		if info.Err != nil {
+			switch {
+			case minio.ToErrorResponse(info.Err).Code == "NoSuchBucket":
+				return false, info.Err.Error(), nil
+			case retryableError(info.Err):
+				return false, fmt.Sprintf("error listing objects: %s", info.Err), nil
+			}
+			return false, "", fmt.Errorf("error listing objects: %s", info.Err)
+		}
+		matched, reason := objectMatches(info, o.MinSize, o.NewerThan)
+		if matched {
+			return true, "", nil
+		}
+		lastCondition = reason
+	}
+	return false, lastCondition, nil
+}
+
+// objectMatches reports whether an object is at least minSize bytes and was modified after
+// newerThan, along with the reason when it is not
+func objectMatches(info minio.ObjectInfo, minSize int64, newerThan time.Time) (bool, string) {
+	switch {
+	case info.Size < minSize:
+		return false, fmt.Sprintf("object %s is %d bytes, expected at least %d", info.Key, info.Size, minSize)
+	case !newerThan.IsZero() && !info.LastModified.After(newerThan):
+		return false, fmt.Sprintf("object %s was last modified at %s, expected after %s", info.Key, info.LastModified.Format(time.RFC3339), newerThan.Format(time.RFC3339))
+	}
+	return true, ""
+}
@@ -0,0 +1,72 @@
+package minio
+
+import "time"
+
+// Config describes how to connect to a MinIO or S3 endpoint and the buckets to wait on.
+// It is read from a config file and can be overridden with flags
+type Config struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Region   string `json:"region,omitempty"`
+	// Secure connects to the endpoint with TLS
+	Secure             bool        `json:"secure,omitempty"`
+	InsecureSkipVerify bool        `json:"insecureSkipVerify,omitempty"`
+	CAFile             string      `json:"caFile,omitempty"`
+	Buckets            []string    `json:"buckets,omitempty"`
+	Credentials        Credentials `json:"credentials,omitempty"`
+}
+
+// Credentials are read from the keys of a Kubernetes Secret when SecretName is set,
+// otherwise from environment variables
+type Credentials struct {
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+	SecretName      string `json:"secretName,omitempty"`
+	AccessKeyKey    string `json:"accessKeyKey,omitempty"`
+	SecretKeyKey    string `json:"secretKeyKey,omitempty"`
+	AccessKeyEnv    string `json:"accessKeyEnv,omitempty"`
+	SecretKeyEnv    string `json:"secretKeyEnv,omitempty"`
+}
+
+type EnsureBucketsCmdOptions struct {
+	SpecFile            string
+	ConfigFile          string
+	DryRun              bool
+	KubeInClusterConfig string
+}
+
+// BucketsSpec lists the buckets to create and configure, read from a spec file
+type BucketsSpec struct {
+	Buckets []BucketSpec `json:"buckets"`
+}
+
+// BucketSpec describes the desired configuration of a bucket. Settings that are not set are left unchanged
+type BucketSpec struct {
+	Name       string `json:"name"`
+	Versioning *bool  `json:"versioning,omitempty"`
+	// ObjectLock can only be enabled when the bucket is created
+	ObjectLock bool           `json:"objectLock,omitempty"`
+	Lifecycle  *LifecycleSpec `json:"lifecycle,omitempty"`
+	// Policy is a JSON bucket policy
+	Policy string `json:"policy,omitempty"`
+}
+
+// LifecycleSpec describes an expiration rule
+type LifecycleSpec struct {
+	Prefix                   string `json:"prefix,omitempty"`
+	ExpirationDays           int    `json:"expirationDays,omitempty"`
+	NoncurrentExpirationDays int    `json:"noncurrentExpirationDays,omitempty"`
+}
+
+// BucketChange is a change applied to a bucket
+type BucketChange struct {
+	Bucket string
+	Change string
+}
+
+// ObjectWaitOptions describes the object to wait for, either a Key or any object under a Prefix
+type ObjectWaitOptions struct {
+	Bucket    string
+	Key       string
+	Prefix    string
+	MinSize   int64
+	NewerThan time.Time
+}
@@ -0,0 +1,206 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// Load reads and validates a plan file
+func Load(fs afero.Fs, path string, defaultTimeoutSeconds int64) (*Plan, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan file %s: %s", path, err)
+	}
+
+	var p Plan
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("error parsing plan file %s: %s", path, err)
+	}
+
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	for i := range p.Targets {
+		if p.Targets[i].TimeoutSeconds == 0 {
+			p.Targets[i].TimeoutSeconds = p.TimeoutSeconds
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that target names are unique, that types are known and
+// that dependsOn references existing targets without forming a cycle
+func (p *Plan) Validate() error {
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("the plan does not contain any targets")
+	}
+
+	targets := make(map[string]Target, len(p.Targets))
+	for _, t := range p.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("every target requires a name")
+		}
+		if _, exists := targets[t.Name]; exists {
+			return fmt.Errorf("target %s is defined more than once", t.Name)
+		}
+		if err := validateTarget(t); err != nil {
+			return fmt.Errorf("target %s: %s", t.Name, err)
+		}
+		targets[t.Name] = t
+	}
+
+	for _, t := range p.Targets {
+		for _, dep := range t.DependsOn {
+			if _, exists := targets[dep]; !exists {
+				return fmt.Errorf("target %s depends on unknown target %s", t.Name, dep)
+			}
+		}
+	}
+
+	// Depth first search for dependency cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(targets))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range targets[name].DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, t := range p.Targets {
+		if err := visit(t.Name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute runs every target concurrently, each one starting once all of its
+// dependencies have succeeded
+// Targets that depend on a failed or skipped target are skipped
+// Results are returned in the order the targets are defined in the plan
+func (p *Plan) Execute(run func(ctx context.Context, t Target) error) []Result {
+	results := make([]Result, len(p.Targets))
+	done := make(map[string]chan struct{}, len(p.Targets))
+	index := make(map[string]int, len(p.Targets))
+	for i, t := range p.Targets {
+		done[t.Name] = make(chan struct{})
+		index[t.Name] = i
+	}
+
+	var wg sync.WaitGroup
+	for i, t := range p.Targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			defer close(done[t.Name])
+
+			for _, dep := range t.DependsOn {
+				<-done[dep]
+				if results[index[dep]].Status != StatusSucceeded {
+					results[i] = Result{
+						Target:  t,
+						Status:  StatusSkipped,
+						Message: fmt.Sprintf("dependency %s did not succeed", dep),
+					}
+					log.Warnf("skipping %s: dependency %s did not succeed", t.Name, dep)
+					return
+				}
+			}
+
+			log.Infof("waiting for %s %s", t.Type, t.Name)
+			results[i] = runWithTimeout(t, run)
+		}(i, t)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// timeoutGracePeriod is how long a target may take to return once its timeout has passed, so
+// that the waiter reports why it timed out rather than a bare timeout
+var timeoutGracePeriod = 5 * time.Second
+
+// runWithTimeout runs a single target and fails it if it does not complete
+// within the target's timeout, the context passed to run expires at the same time
+func runWithTimeout(t Target, run func(ctx context.Context, t Target) error) Result {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- run(ctx, t)
+	}()
+
+	// The waiters stop on their own once ctx is done, only give up on those that do not
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		select {
+		case err = <-errChan:
+		case <-time.After(timeoutGracePeriod):
+			err = fmt.Errorf("timed out after %v seconds", t.TimeoutSeconds)
+		}
+	}
+
+	result := Result{Target: t, Duration: time.Since(start).Round(time.Second)}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Message = err.Error()
+		log.Errorf("%s %s failed: %s", t.Type, t.Name, err)
+		return result
+	}
+	result.Status = StatusSucceeded
+	log.Infof("%s %s is ready", t.Type, t.Name)
+	return result
+}
+
+// PrintSummary writes a table summarizing the results
+func PrintSummary(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tSTATUS\tDURATION\tMESSAGE")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Target.Name, r.Target.Type, r.Status, r.Duration, r.Message)
+	}
+	tw.Flush()
+}
+
+// Failed reports whether any of the results did not succeed
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status != StatusSucceeded {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/waiter"
+	"github.com/spf13/afero"
+)
+
+func TestLoad(t *testing.T) {
+	appFS := afero.NewMemMapFs()
+	afero.WriteFile(appFS, "/plan.yaml", []byte(`
+timeoutSeconds: 120
+targets:
+  - name: vault
+    type: statefulset
+    namespace: vault
+    label: app.kubernetes.io/name=vault
+  - name: unseal
+    type: vault-unseal
+    timeoutSeconds: 30
+    dependsOn: [vault]
+  - name: apps
+    type: argocd-app
+    namespace: argocd
+    label: app.kubernetes.io/part-of=platform
+    sync: []
+`), 0644)
+	afero.WriteFile(appFS, "/unknown-field.yaml", []byte(`
+targets:
+  - name: vault
+    type: vault-unseal
+    timeout: 30
+`), 0644)
+	afero.WriteFile(appFS, "/other-type-field.yaml", []byte(`
+targets:
+  - name: postgres
+    type: tcp
+    address: postgres.db.svc.cluster.local:5432
+    quorum: 2
+`), 0644)
+
+	p, err := Load(appFS, "/plan.yaml", 60)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := p.Targets[0].TimeoutSeconds; got != 120 {
+		t.Errorf("plan timeout not applied to target, got %v", got)
+	}
+	if got := p.Targets[1].TimeoutSeconds; got != 30 {
+		t.Errorf("target timeout overridden, got %v", got)
+	}
+	// Options that are not set keep the defaults of the wait-for command
+	if got := p.Targets[1].Waiter.(*waiter.VaultUnsealOptions).Namespace; got != "vault" {
+		t.Errorf("expected the default vault namespace, got %v", got)
+	}
+	// An empty sync list ignores the sync status rather than defaulting to Synced
+	if got := p.Targets[2].Waiter.(*waiter.ArgoCDAppOptions).Sync; got == nil || len(got) != 0 {
+		t.Errorf("expected an empty sync list, got %#v", got)
+	}
+
+	if _, err := Load(appFS, "/unknown-field.yaml", 60); err == nil {
+		t.Errorf("Load() expected an error for unknown fields")
+	}
+	if _, err := Load(appFS, "/other-type-field.yaml", 60); err == nil {
+		t.Errorf("Load() expected an error for the fields of another type")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []Target
+		wantErr bool
+	}{
+		{
+			name: "valid plan",
+			targets: []Target{
+				{Name: "a", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions()},
+				{Name: "b", Type: waiter.KindVaultInitComplete, Waiter: waiter.NewVaultInitCompleteOptions(), DependsOn: []string{"a"}},
+			},
+		},
+		{
+			name:    "empty plan",
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			targets: []Target{
+				{Name: "a", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions()},
+				{Name: "a", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions()},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			targets: []Target{{Name: "a", Type: "unknown"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing fields",
+			targets: []Target{{Name: "a", Type: waiter.KindDeployment, Waiter: &waiter.DNSOptions{}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown dependency",
+			targets: []Target{{Name: "a", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions(), DependsOn: []string{"b"}}},
+			wantErr: true,
+		},
+		{
+			name: "dependency cycle",
+			targets: []Target{
+				{Name: "a", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions(), DependsOn: []string{"c"}},
+				{Name: "b", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions(), DependsOn: []string{"a"}},
+				{Name: "c", Type: waiter.KindVaultUnseal, Waiter: waiter.NewVaultUnsealOptions(), DependsOn: []string{"b"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plan{Targets: tt.targets}
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	p := &Plan{Targets: []Target{
+		{Name: "a", Type: waiter.KindVaultUnseal, TimeoutSeconds: 5},
+		{Name: "b", Type: waiter.KindVaultUnseal, TimeoutSeconds: 5, DependsOn: []string{"a"}},
+		{Name: "fails", Type: waiter.KindVaultUnseal, TimeoutSeconds: 5},
+		{Name: "skipped", Type: waiter.KindVaultUnseal, TimeoutSeconds: 5, DependsOn: []string{"b", "fails"}},
+	}}
+
+	var mu sync.Mutex
+	var order []string
+	results := p.Execute(func(_ context.Context, t Target) error {
+		mu.Lock()
+		order = append(order, t.Name)
+		mu.Unlock()
+		if t.Name == "fails" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+
+	want := []string{StatusSucceeded, StatusSucceeded, StatusFailed, StatusSkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("target %s status = %s, want %s", r.Target.Name, r.Status, want[i])
+		}
+	}
+
+	for i, name := range order {
+		if name == "b" {
+			for _, before := range order[i:] {
+				if before == "a" {
+					t.Errorf("target b ran before its dependency a: %v", order)
+				}
+			}
+		}
+		if name == "skipped" {
+			t.Errorf("target skipped should not have run")
+		}
+	}
+
+	if !Failed(results) {
+		t.Errorf("Failed() = false, want true")
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	gracePeriod := timeoutGracePeriod
+	timeoutGracePeriod = 100 * time.Millisecond
+	defer func() { timeoutGracePeriod = gracePeriod }()
+
+	target := Target{Name: "a", Type: waiter.KindVaultUnseal, TimeoutSeconds: 1}
+
+	// The error of a waiter that honours the context is reported rather than a bare timeout
+	result := runWithTimeout(target, func(ctx context.Context, t Target) error {
+		<-ctx.Done()
+		return fmt.Errorf("2 of 3 instances are unsealed")
+	})
+	if result.Status != StatusFailed || result.Message != "2 of 3 instances are unsealed" {
+		t.Errorf("unexpected result %s: %s", result.Status, result.Message)
+	}
+
+	result = runWithTimeout(target, func(ctx context.Context, t Target) error {
+		time.Sleep(2 * time.Second)
+		return nil
+	})
+	if result.Status != StatusFailed || result.Message != "timed out after 1 seconds" {
+		t.Errorf("unexpected result %s: %s", result.Status, result.Message)
+	}
+}
@@ -0,0 +1,57 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/waiter"
+)
+
+// targetFields are the fields of a target that are not options of its type
+var targetFields = []string{"name", "type", "timeoutSeconds", "dependsOn"}
+
+// UnmarshalJSON decodes the fields common to every target, then decodes the other fields into
+// the options of the target's type, rejecting the fields that the type does not have
+func (t *Target) UnmarshalJSON(data []byte) error {
+	// target does not have the UnmarshalJSON method, so that decoding it does not recurse
+	type target Target
+	var common target
+	if err := json.Unmarshal(data, &common); err != nil {
+		return err
+	}
+	*t = Target(common)
+
+	// Targets of an unknown type are reported by Validate
+	w, err := waiter.New(t.Type)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, name := range targetFields {
+		delete(fields, name)
+	}
+	options, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(options))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(w); err != nil {
+		return fmt.Errorf("target %s: %s", t.Name, err)
+	}
+	t.Waiter = w
+	return nil
+}
+
+// validateTarget checks that a target has the options required by its type
+func validateTarget(t Target) error {
+	if t.Waiter == nil {
+		return fmt.Errorf("unknown target type %q", t.Type)
+	}
+	return t.Waiter.Validate()
+}
@@ -0,0 +1,41 @@
+package plan
+
+import (
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/waiter"
+)
+
+const (
+	StatusSucceeded = "Succeeded"
+	StatusFailed    = "Failed"
+	StatusSkipped   = "Skipped"
+)
+
+// Plan is a list of targets to wait on, read from a plan file
+type Plan struct {
+	// TimeoutSeconds is applied to targets that do not set their own timeout
+	TimeoutSeconds int64    `json:"timeoutSeconds,omitempty"`
+	Targets        []Target `json:"targets"`
+}
+
+// Target describes a single thing to wait on
+// Every other field of a target is decoded into the options of its type, which are the
+// options of the matching wait-for command
+type Target struct {
+	// Name uniquely identifies the target within the plan
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	TimeoutSeconds int64    `json:"timeoutSeconds,omitempty"`
+	DependsOn      []string `json:"dependsOn,omitempty"`
+	// Waiter holds the options of the target's type
+	Waiter waiter.Waiter `json:"-"`
+}
+
+// Result is the outcome of waiting on a Target
+type Result struct {
+	Target   Target
+	Status   string
+	Duration time.Duration
+	Message  string
+}
@@ -0,0 +1,131 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	log "github.com/sirupsen/logrus"
+)
+
+// WaitForDNS waits for a DNS name to resolve to the expected records until ctx is done
+func WaitForDNS(ctx context.Context, o *DNSOptions) error {
+	resolver := newResolver(o.Nameserver)
+	recordType := strings.ToUpper(o.RecordType)
+	switch recordType {
+	case "A", "AAAA", "CNAME", "TXT":
+	default:
+		return fmt.Errorf("unsupported record type %s, please use one of A, AAAA, CNAME or TXT", o.RecordType)
+	}
+
+	log.Infof("waiting for %s record %s to resolve - this could take up to %v", recordType, o.Name, kubernetes.TimeRemaining(ctx))
+
+	var lastErr error
+	err := kubernetes.PollUntil(ctx, time.Second, nil, func(ctx context.Context) (bool, error) {
+		answers, err := lookup(ctx, resolver, recordType, o.Name)
+		switch {
+		case err != nil:
+			lastErr = err
+		default:
+			missing := missingRecords(answers, o.Expect)
+			if len(missing) == 0 {
+				log.Infof("%s resolved to %s", o.Name, strings.Join(answers, ", "))
+				return true, nil
+			}
+			lastErr = fmt.Errorf("%s resolved to %s, missing %s", o.Name, strings.Join(answers, ", "), strings.Join(missing, ", "))
+		}
+		log.Infof("waiting for %s: %s", o.Name, lastErr)
+		return false, nil
+	})
+	if errors.Is(err, kubernetes.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for %s record %s: %s", recordType, o.Name, lastErr)
+	}
+	return err
+}
+
+// newResolver returns a resolver querying the provided nameserver, or the system resolver
+func newResolver(nameserver string) *net.Resolver {
+	if nameserver == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: dialTimeout}
+			return dialer.DialContext(ctx, network, nameserver)
+		},
+	}
+}
+
+// lookup resolves a single record type and returns the answers as strings, the lookup is
+// abandoned when ctx is done
+func lookup(ctx context.Context, resolver *net.Resolver, recordType string, name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	switch recordType {
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return cnameAnswers(name, cname)
+	case "TXT":
+		return resolver.LookupTXT(ctx, name)
+	default:
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		answers := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+		return answers, nil
+	}
+}
+
+// cnameAnswers returns the target of a CNAME record, LookupCNAME returns the queried name
+// itself when the name only has A or AAAA records, which is reported as a missing record
+func cnameAnswers(name string, cname string) ([]string, error) {
+	if normalizeRecord(cname) == normalizeRecord(name) {
+		return nil, fmt.Errorf("%s has no CNAME record", name)
+	}
+	return []string{cname}, nil
+}
+
+// missingRecords returns the expected values that are not among the answers, ignoring case
+// and the trailing dot of fully qualified names
+func missingRecords(answers []string, expected []string) []string {
+	found := map[string]bool{}
+	for _, answer := range answers {
+		found[normalizeRecord(answer)] = true
+	}
+	var missing []string
+	for _, value := range expected {
+		if !found[normalizeRecord(value)] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+// normalizeRecord returns a record value in a comparable form
+func normalizeRecord(value string) string {
+	value = strings.TrimSpace(value)
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+	return strings.ToLower(strings.TrimSuffix(value, "."))
+}
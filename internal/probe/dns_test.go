@@ -0,0 +1,53 @@
+package probe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  []string
+		expected []string
+		want     []string
+	}{
+		{name: "no expectation", answers: []string{"10.0.0.1"}},
+		{name: "all expected present", answers: []string{"10.0.0.1", "10.0.0.2"}, expected: []string{"10.0.0.2"}},
+		{name: "missing address", answers: []string{"10.0.0.1"}, expected: []string{"10.0.0.1", "10.0.0.3"}, want: []string{"10.0.0.3"}},
+		{name: "fully qualified names", answers: []string{"Ingress.Example.com."}, expected: []string{"ingress.example.com"}},
+		{name: "ipv6 notation", answers: []string{"2001:db8::1"}, expected: []string{"2001:0db8:0:0:0:0:0:1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingRecords(tt.answers, tt.expected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCNAMEAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		cname   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "alias", query: "argocd.example.com", cname: "ingress.example.com.", want: []string{"ingress.example.com."}},
+		{name: "only address records", query: "ingress.example.com", cname: "ingress.example.com.", wantErr: true},
+		{name: "fully qualified query", query: "Ingress.Example.com.", cname: "ingress.example.com.", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cnameAnswers(tt.query, tt.cname)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cnameAnswers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cnameAnswers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// requestTimeout bounds a single probe so that a hung connection does not use the whole timeout
+	requestTimeout = 10 * time.Second
+	// maxBodyBytes is the maximum size of a response body matched against the expected body regex
+	maxBodyBytes = 1 << 20
+)
+
+// DefaultCAKey is the key of the CA bundle in a Secret or ConfigMap
+const DefaultCAKey = "ca.crt"
+
+// Load reads the CA bundle and client certificate described by s into o, from Kubernetes or
+// from files
+func (s TLSSource) Load(inCluster string, o *HTTPOptions) error {
+	var caData map[string]string
+	var err error
+	if s.Namespace == "" && (s.CASecret != "" || s.CAConfigMap != "" || s.ClientCertSecret != "") {
+		return fmt.Errorf("please provide the namespace of the CA bundle and client certificate Secret or ConfigMap")
+	}
+	switch {
+	case s.CASecret != "" && s.CAConfigMap != "":
+		return fmt.Errorf("please provide only one of a CA Secret or a CA ConfigMap")
+	case s.CASecret != "":
+		caData, err = kubernetes.ReadSecretV2(inCluster, s.Namespace, s.CASecret)
+	case s.CAConfigMap != "":
+		caData, err = kubernetes.ReadConfigMapV2(inCluster, s.Namespace, s.CAConfigMap)
+	}
+	if err != nil {
+		return err
+	}
+	if caData != nil {
+		caKey := s.CAKey
+		if caKey == "" {
+			caKey = DefaultCAKey
+		}
+		caBundle, ok := caData[caKey]
+		if !ok {
+			return fmt.Errorf("the CA bundle does not contain key %s", caKey)
+		}
+		o.CABundle = []byte(caBundle)
+	}
+
+	switch {
+	case s.ClientCertSecret != "":
+		certData, err := kubernetes.ReadSecretV2(inCluster, s.Namespace, s.ClientCertSecret)
+		if err != nil {
+			return err
+		}
+		o.ClientCert = []byte(certData[v1.TLSCertKey])
+		o.ClientKey = []byte(certData[v1.TLSPrivateKeyKey])
+	case s.ClientCertFile != "" || s.ClientKeyFile != "":
+		o.ClientCert, err = os.ReadFile(s.ClientCertFile)
+		if err != nil {
+			return fmt.Errorf("error reading client certificate: %s", err)
+		}
+		o.ClientKey, err = os.ReadFile(s.ClientKeyFile)
+		if err != nil {
+			return fmt.Errorf("error reading client key: %s", err)
+		}
+	}
+	return nil
+}
+
+// WaitForHTTP probes an HTTP endpoint every second until it returns the expected status code
+// and, when set, a body matching the expected regex, or until ctx is done
+func WaitForHTTP(ctx context.Context, o *HTTPOptions) error {
+	client, err := newHTTPClient(o)
+	if err != nil {
+		return err
+	}
+	var bodyRegex *regexp.Regexp
+	if o.ExpectBodyRegex != "" {
+		bodyRegex, err = regexp.Compile(o.ExpectBodyRegex)
+		if err != nil {
+			return fmt.Errorf("please check the provided body regex %s: %s", o.ExpectBodyRegex, err)
+		}
+	}
+
+	log.Infof("waiting for %s to return %d - this could take up to %v", o.URL, o.ExpectStatus, kubernetes.TimeRemaining(ctx))
+
+	var lastErr error
+	err = kubernetes.PollUntil(ctx, time.Second, nil, func(ctx context.Context) (bool, error) {
+		lastErr = probeHTTP(ctx, client, o.URL, o.ExpectStatus, bodyRegex)
+		if lastErr != nil {
+			log.Infof("waiting for %s: %s", o.URL, lastErr)
+			return false, nil
+		}
+		log.Infof("%s is available", o.URL)
+		return true, nil
+	})
+	if errors.Is(err, kubernetes.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for %s: %s", o.URL, lastErr)
+	}
+	return err
+}
+
+// probeHTTP sends a single GET request and checks the response against the expectations,
+// the request is abandoned when ctx is done
+func probeHTTP(ctx context.Context, client *http.Client, url string, expectStatus int, bodyRegex *regexp.Regexp) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectStatus {
+		return fmt.Errorf("got status %d, expected %d", resp.StatusCode, expectStatus)
+	}
+	if bodyRegex == nil {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return fmt.Errorf("error reading response body: %s", err)
+	}
+	if !bodyRegex.Match(body) {
+		return fmt.Errorf("response body does not match %s", bodyRegex)
+	}
+	return nil
+}
+
+// newHTTPClient returns a client using the TLS options of the probe
+func newHTTPClient(o *HTTPOptions) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if len(o.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(o.CABundle) {
+			return nil, fmt.Errorf("the CA bundle does not contain any PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(o.ClientCert) > 0 || len(o.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,90 @@
+package probe
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForHTTP(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.Write([]byte(`{"status":"ok"}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	tests := []struct {
+		name    string
+		options HTTPOptions
+		wantErr string
+	}{
+		{
+			name:    "trusted CA bundle and matching body",
+			options: HTTPOptions{URL: server.URL + "/healthz", ExpectStatus: 200, ExpectBodyRegex: `"status":\s*"ok"`, CABundle: caBundle},
+		},
+		{
+			name:    "insecure skip verify",
+			options: HTTPOptions{URL: server.URL + "/healthz", ExpectStatus: 200, InsecureSkipVerify: true},
+		},
+		{
+			name:    "untrusted certificate",
+			options: HTTPOptions{URL: server.URL + "/healthz", ExpectStatus: 200},
+			wantErr: "certificate",
+		},
+		{
+			name:    "unexpected status",
+			options: HTTPOptions{URL: server.URL + "/ready", ExpectStatus: 200, CABundle: caBundle},
+			wantErr: "got status 503, expected 200",
+		},
+		{
+			name:    "body does not match",
+			options: HTTPOptions{URL: server.URL + "/healthz", ExpectStatus: 200, ExpectBodyRegex: "degraded", CABundle: caBundle},
+			wantErr: "response body does not match degraded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			err := WaitForHTTP(ctx, &tt.options)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("WaitForHTTP() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("WaitForHTTP() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSSourceLoad(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	os.WriteFile(certFile, []byte("certificate"), 0o600)
+	os.WriteFile(keyFile, []byte("key"), 0o600)
+
+	o := &HTTPOptions{}
+	if err := (TLSSource{ClientCertFile: certFile, ClientKeyFile: keyFile}).Load("false", o); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(o.ClientCert) != "certificate" || string(o.ClientKey) != "key" {
+		t.Errorf("client certificate and key not read from the files")
+	}
+
+	if err := (TLSSource{Namespace: "default", CASecret: "ca", CAConfigMap: "ca"}).Load("false", o); err == nil {
+		t.Errorf("expected an error when both a CA Secret and ConfigMap are provided")
+	}
+	if err := (TLSSource{ClientCertSecret: "client-tls"}).Load("false", o); err == nil {
+		t.Errorf("expected an error when the client certificate Secret has no namespace")
+	}
+}
@@ -0,0 +1,38 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	log "github.com/sirupsen/logrus"
+)
+
+// dialTimeout bounds a single connection attempt
+const dialTimeout = 5 * time.Second
+
+// WaitForTCP waits for a host:port address to accept TCP connections until ctx is done
+func WaitForTCP(ctx context.Context, address string) error {
+	log.Infof("waiting for %s to accept connections - this could take up to %v", address, kubernetes.TimeRemaining(ctx))
+
+	var lastErr error
+	err := kubernetes.PollUntil(ctx, time.Second, nil, func(ctx context.Context) (bool, error) {
+		dialer := net.Dialer{Timeout: dialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			lastErr = err
+			log.Infof("waiting for %s: %s", address, err)
+			return false, nil
+		}
+		conn.Close()
+		log.Infof("%s is accepting connections", address)
+		return true, nil
+	})
+	if errors.Is(err, kubernetes.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for %s to accept connections: %s", address, lastErr)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package probe
+
+// HTTPOptions describes the HTTP endpoint to probe and the response to expect
+type HTTPOptions struct {
+	URL             string
+	ExpectStatus    int
+	ExpectBodyRegex string
+	// CABundle contains PEM encoded certificates trusted in addition to the system roots
+	CABundle           []byte
+	InsecureSkipVerify bool
+	// ClientCert and ClientKey contain a PEM encoded client certificate and key
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// TLSSource describes where the CA bundle and client certificate of an HTTP probe are read from
+type TLSSource struct {
+	// Namespace contains the Secrets and ConfigMap below
+	Namespace string
+	// The CA bundle is read from the CAKey of either CASecret or CAConfigMap
+	CASecret    string
+	CAConfigMap string
+	CAKey       string
+	// The client certificate is read from the TLS Secret ClientCertSecret, or from the PEM files
+	// ClientCertFile and ClientKeyFile
+	ClientCertSecret string
+	ClientCertFile   string
+	ClientKeyFile    string
+}
+
+// DNSOptions describes the DNS name to resolve and the records to expect
+type DNSOptions struct {
+	Name string
+	// RecordType is one of A, AAAA, CNAME or TXT
+	RecordType string
+	// Expect contains values that must all be returned, any answer is accepted when it is empty
+	Expect []string
+	// Nameserver is the host[:port] of the nameserver to query instead of the system resolver
+	Nameserver string
+}
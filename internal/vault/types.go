@@ -0,0 +1,37 @@
+package vault
+
+// UnsealWaitOptions describes the Vault instances to check the seal status of. Instances are
+// reached at Addresses when set, otherwise at the IP of every Pod matching LabelSelector in Namespace
+type UnsealWaitOptions struct {
+	Addresses          []string
+	Namespace          string
+	LabelSelector      string
+	Scheme             string
+	Port               int
+	InsecureSkipVerify bool
+	// Quorum is the number of instances that must be unsealed, all instances when it is 0
+	Quorum int
+}
+
+// InitCompleteWaitOptions describes the Vault secrets that must be readable once Vault has been
+// configured, and how to authenticate to read them
+type InitCompleteWaitOptions struct {
+	Address string
+	Paths   []string
+	// KVMount and KVVersion are used to build the API path of secrets stored in a KV v2 mount
+	KVMount   string
+	KVVersion int
+	// AuthMethod is either token or kubernetes
+	AuthMethod string
+	// The token is read from TokenFile, from the TokenSecretKey of a Secret, or from the TokenEnv variable
+	TokenEnv             string
+	TokenFile            string
+	TokenSecretNamespace string
+	TokenSecretName      string
+	TokenSecretKey       string
+	InCluster            string
+	// Kubernetes auth logs in to KubernetesMount with the JWT in ServiceAccountTokenFile
+	KubernetesRole          string
+	KubernetesMount         string
+	ServiceAccountTokenFile string
+}
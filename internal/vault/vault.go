@@ -0,0 +1,317 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgo "k8s.io/client-go/kubernetes"
+)
+
+const (
+	DefaultAddress       = "http://vault.vault.svc.cluster.local:8200"
+	DefaultCheckPath     = "secret/development/metaphor"
+	DefaultKVMount       = "secret"
+	DefaultKVVersion     = 2
+	DefaultNamespace     = "vault"
+	DefaultLabelSelector = "app.kubernetes.io/name=vault,component=server"
+	DefaultScheme        = "http"
+	DefaultPort          = 8200
+
+	DefaultTokenEnv                = "VAULT_TOKEN"
+	DefaultTokenSecretKey          = "root-token"
+	DefaultKubernetesMount         = "kubernetes"
+	DefaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	AuthMethodToken      = "token"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+// progressInterval is the minimum time between two progress messages while waiting for vault
+const progressInterval = 10 * time.Second
+
+// WaitForUnseal waits until the quorum of Vault instances report that they are initialized
+// and unsealed through sys/health and sys/seal-status, or until ctx is done. clientset is used to
+// list the vault pods and may be nil when o.Addresses is set
+func WaitForUnseal(ctx context.Context, clientset *clientgo.Clientset, o *UnsealWaitOptions) error {
+	log.Infof("waiting for vault to be unsealed - this could take up to %v", kubernetes.TimeRemaining(ctx))
+
+	var lastCondition string
+	var lastLogged time.Time
+	err := kubernetes.PollUntil(ctx, time.Second, nil, func(ctx context.Context) (bool, error) {
+		statuses, err := sealStatuses(ctx, clientset, o)
+		switch {
+		case err != nil && (apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)):
+			return false, err
+		case err != nil:
+			// The API server may be briefly unavailable while the cluster is being created
+			lastCondition = err.Error()
+		default:
+			var unsealed bool
+			unsealed, lastCondition = sealQuorumReached(statuses, o.Quorum)
+			if unsealed {
+				log.Infof("vault successfully unsealed: %s", lastCondition)
+				return true, nil
+			}
+		}
+
+		if time.Since(lastLogged) >= progressInterval {
+			log.Infof("waiting for vault to be unsealed: %s", lastCondition)
+			lastLogged = time.Now()
+		}
+		return false, nil
+	})
+	if errors.Is(err, kubernetes.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for vault to be unsealed: %s", lastCondition)
+	}
+	return err
+}
+
+// instanceSealStatus is the seal status reported by a single Vault instance
+type instanceSealStatus struct {
+	Name     string
+	Unsealed bool
+	Status   string
+}
+
+// sealStatuses queries the seal status of every Vault instance
+func sealStatuses(ctx context.Context, clientset *clientgo.Clientset, o *UnsealWaitOptions) ([]instanceSealStatus, error) {
+	if len(o.Addresses) > 0 {
+		statuses := make([]instanceSealStatus, 0, len(o.Addresses))
+		for _, address := range o.Addresses {
+			statuses = append(statuses, sealStatus(ctx, address, address, o.InsecureSkipVerify))
+		}
+		return statuses, nil
+	}
+
+	pods, err := clientset.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing vault pods: %w", err)
+	}
+
+	statuses := make([]instanceSealStatus, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == "" {
+			statuses = append(statuses, instanceSealStatus{Name: pod.Name, Status: "not assigned an IP"})
+			continue
+		}
+		address := fmt.Sprintf("%s://%s", o.Scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(o.Port)))
+		statuses = append(statuses, sealStatus(ctx, pod.Name, address, o.InsecureSkipVerify))
+	}
+	return statuses, nil
+}
+
+// sealStatus queries sys/health and sys/seal-status of the Vault instance at address, giving up
+// when ctx is done
+func sealStatus(ctx context.Context, name string, address string, insecureSkipVerify bool) instanceSealStatus {
+	status := instanceSealStatus{Name: name}
+
+	cfg := api.DefaultConfig()
+	cfg.Address = address
+	if err := cfg.ConfigureTLS(&api.TLSConfig{Insecure: insecureSkipVerify}); err != nil {
+		status.Status = err.Error()
+		return status
+	}
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		status.Status = fmt.Sprintf("error creating vault client: %s", err)
+		return status
+	}
+
+	health, err := client.Sys().HealthWithContext(ctx)
+	if err != nil {
+		status.Status = err.Error()
+		return status
+	}
+	if !health.Initialized {
+		status.Status = "not initialized"
+		return status
+	}
+	seal, err := client.Sys().SealStatusWithContext(ctx)
+	if err != nil {
+		status.Status = err.Error()
+		return status
+	}
+
+	status.Unsealed = !seal.Sealed && !health.Sealed
+	if status.Unsealed {
+		status.Status = "unsealed"
+	} else {
+		status.Status = fmt.Sprintf("sealed, %d/%d unseal keys provided", seal.Progress, seal.T)
+	}
+	return status
+}
+
+// sealQuorumReached reports whether quorum instances, or all of them when quorum is 0,
+// are unsealed, along with a description of the status of every instance
+func sealQuorumReached(statuses []instanceSealStatus, quorum int) (bool, string) {
+	if len(statuses) == 0 {
+		return false, "no vault instance was found"
+	}
+	required := quorum
+	if required <= 0 {
+		required = len(statuses)
+	}
+
+	var unsealed int
+	descriptions := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Unsealed {
+			unsealed++
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", status.Name, status.Status))
+	}
+	// Keep waiting for the instances that are not scheduled yet rather than lowering the quorum
+	if len(statuses) < required {
+		return false, fmt.Sprintf("%d/%d instances found (%s)", len(statuses), required, strings.Join(descriptions, "; "))
+	}
+	return unsealed >= required, fmt.Sprintf("%d/%d instances unsealed (%s)", unsealed, len(statuses), strings.Join(descriptions, "; "))
+}
+
+// WaitForInitComplete waits until every secret path can be read from vault, which indicates
+// that terraform has finished configuring it, or until ctx is done
+func WaitForInitComplete(ctx context.Context, o *InitCompleteWaitOptions) error {
+	cfg := api.DefaultConfig()
+	cfg.Address = o.Address
+
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating vault client: %s", err)
+	}
+	switch o.AuthMethod {
+	case AuthMethodToken:
+	case AuthMethodKubernetes:
+		if o.KubernetesRole == "" {
+			return fmt.Errorf("please provide the role to log in with when using the %s auth method", AuthMethodKubernetes)
+		}
+	default:
+		return fmt.Errorf("unsupported auth method %s, please use %s or %s", o.AuthMethod, AuthMethodToken, AuthMethodKubernetes)
+	}
+	if o.KVVersion != 1 && o.KVVersion != 2 {
+		return fmt.Errorf("unsupported KV version %d, please use 1 or 2", o.KVVersion)
+	}
+	paths := make([]string, 0, len(o.Paths))
+	for _, path := range o.Paths {
+		paths = append(paths, kvAPIPath(path, o.KVMount, o.KVVersion))
+	}
+
+	log.Infof("waiting for vault secrets %s - this could take up to %v", strings.Join(paths, ", "), kubernetes.TimeRemaining(ctx))
+
+	var authenticated bool
+	var lastErr error
+	err = kubernetes.PollUntil(ctx, 5*time.Second, nil, func(ctx context.Context) (bool, error) {
+		// The auth method is usually configured by the same terraform run, so keep retrying the login
+		if !authenticated {
+			lastErr = authenticate(ctx, client, o)
+			authenticated = lastErr == nil
+		}
+		if authenticated {
+			lastErr = readPaths(ctx, client, paths)
+			if lastErr == nil {
+				return true, nil
+			}
+		}
+		log.Infof("waiting for vault to terraform to apply, sleeping 5 seconds: %s", lastErr)
+		return false, nil
+	})
+	if errors.Is(err, kubernetes.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for vault to be configured: %s", lastErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("vault successfully hydrated")
+	return nil
+}
+
+// readPaths returns an error unless every path can be read from vault before ctx is done
+func readPaths(ctx context.Context, client *api.Client, paths []string) error {
+	for _, path := range paths {
+		// Read the secret from the Vault server
+		secret, err := client.Logical().ReadWithContext(ctx, path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", path, err)
+		}
+		// Check if the secret was found
+		if secret == nil {
+			return fmt.Errorf("secret %s was not found", path)
+		}
+	}
+	return nil
+}
+
+// authenticate sets the token of the vault client using the configured auth method, giving up
+// on the login when ctx is done
+func authenticate(ctx context.Context, client *api.Client, o *InitCompleteWaitOptions) error {
+	if o.AuthMethod == AuthMethodKubernetes {
+		jwt, err := os.ReadFile(o.ServiceAccountTokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading service account token: %s", err)
+		}
+		secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", o.KubernetesMount), map[string]interface{}{
+			"role": o.KubernetesRole,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		})
+		if err != nil {
+			return fmt.Errorf("error logging in with kubernetes auth: %s", err)
+		}
+		if secret == nil || secret.Auth == nil {
+			return fmt.Errorf("kubernetes auth login did not return a token")
+		}
+		client.SetToken(secret.Auth.ClientToken)
+		return nil
+	}
+
+	var token string
+	switch {
+	case o.TokenFile != "":
+		data, err := os.ReadFile(o.TokenFile)
+		if err != nil {
+			return fmt.Errorf("error reading token file: %s", err)
+		}
+		token = strings.TrimSpace(string(data))
+	case o.TokenSecretName != "":
+		data, err := kubernetes.ReadSecretV2(o.InCluster, o.TokenSecretNamespace, o.TokenSecretName)
+		if err != nil {
+			return err
+		}
+		token = data[o.TokenSecretKey]
+		if token == "" {
+			return fmt.Errorf("secret %s does not contain key %s", o.TokenSecretName, o.TokenSecretKey)
+		}
+	case o.TokenEnv != "":
+		token = os.Getenv(o.TokenEnv)
+	}
+	// Without a token, secrets are read with the token the client found in its environment, if any
+	if token != "" {
+		client.SetToken(token)
+	}
+	return nil
+}
+
+// kvAPIPath returns the API path used to read a secret, inserting data/ after the mount for KV v2
+func kvAPIPath(path string, mount string, version int) string {
+	path = strings.Trim(path, "/")
+	mount = strings.Trim(mount, "/")
+	if version != 2 || !strings.HasPrefix(path, mount+"/") {
+		return path
+	}
+	secretPath := strings.TrimPrefix(path, mount+"/")
+	if strings.HasPrefix(secretPath, "data/") {
+		return path
+	}
+	return fmt.Sprintf("%s/data/%s", mount, secretPath)
+}
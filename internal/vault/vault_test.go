@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSealQuorumReached(t *testing.T) {
+	statuses := []instanceSealStatus{
+		{Name: "vault-0", Unsealed: true, Status: "unsealed"},
+		{Name: "vault-1", Unsealed: true, Status: "unsealed"},
+		{Name: "vault-2", Status: "sealed, 1/3 unseal keys provided"},
+	}
+	tests := []struct {
+		name     string
+		statuses []instanceSealStatus
+		quorum   int
+		want     bool
+	}{
+		{name: "all instances required", statuses: statuses, quorum: 0, want: false},
+		{name: "quorum reached", statuses: statuses, quorum: 2, want: true},
+		{name: "quorum larger than the instances found", statuses: statuses[:2], quorum: 3, want: false},
+		{name: "no instance found", statuses: nil, quorum: 0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := sealQuorumReached(tt.statuses, tt.quorum); got != tt.want {
+				t.Errorf("sealQuorumReached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, description := sealQuorumReached(statuses, 0)
+	want := "2/3 instances unsealed (vault-0: unsealed; vault-1: unsealed; vault-2: sealed, 1/3 unseal keys provided)"
+	if description != want {
+		t.Errorf("sealQuorumReached() = %s, want %s", description, want)
+	}
+
+	_, description = sealQuorumReached(statuses[:1], 3)
+	want = "1/3 instances found (vault-0: unsealed)"
+	if description != want {
+		t.Errorf("sealQuorumReached() = %s, want %s", description, want)
+	}
+}
+
+func TestKVAPIPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		mount   string
+		version int
+		want    string
+	}{
+		{name: "kv v2 path", path: "secret/development/metaphor", mount: "secret", version: 2, want: "secret/data/development/metaphor"},
+		{name: "kv v2 api path is kept", path: "secret/data/development/metaphor", mount: "secret", version: 2, want: "secret/data/development/metaphor"},
+		{name: "kv v1 path", path: "secret/development/metaphor", mount: "secret", version: 1, want: "secret/development/metaphor"},
+		{name: "other mount", path: "users/admin", mount: "secret", version: 2, want: "users/admin"},
+		{name: "slashes are trimmed", path: "/kv/app/", mount: "kv/", version: 2, want: "kv/data/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kvAPIPath(tt.path, tt.mount, tt.version); got != tt.want {
+				t.Errorf("kvAPIPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForInitCompleteRequiresKubernetesRole(t *testing.T) {
+	o := &InitCompleteWaitOptions{
+		Address:    DefaultAddress,
+		Paths:      []string{DefaultCheckPath},
+		KVMount:    DefaultKVMount,
+		KVVersion:  DefaultKVVersion,
+		AuthMethod: AuthMethodKubernetes,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+	if err := WaitForInitComplete(ctx, o); err == nil {
+		t.Errorf("expected an error without a kubernetes role")
+	}
+}
@@ -0,0 +1,608 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	clientgo "k8s.io/client-go/kubernetes"
+)
+
+// objectKind waits on the objects of one of the kinds of ObjectOptions
+type objectKind struct {
+	apiVersion string
+	kind       string
+	// waitOne waits for the first matching object to be ready
+	waitOne func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error
+	// waitAll waits for the matching objects to be ready, it is nil for kinds that only wait on one
+	waitAll func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error
+}
+
+var objectKinds = map[string]objectKind{
+	KindDeployment: {
+		apiVersion: "apps/v1",
+		kind:       "Deployment",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			deployment, err := kubernetes.ReturnDeploymentObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving deployment object: %s", err)
+			}
+			if _, err := kubernetes.WaitForDeploymentReady(ctx, clientset, deployment); err != nil {
+				return fmt.Errorf("error waiting for deployment object: %s", err)
+			}
+			return nil
+		},
+		waitAll: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			deployments, err := kubernetes.ReturnDeploymentObjects(ctx, clientset, o.selector(), o.Namespace, o.MinCount)
+			if err != nil {
+				return fmt.Errorf("error retrieving deployment objects: %s", err)
+			}
+			names := make([]string, len(deployments))
+			for i := range deployments {
+				names[i] = deployments[i].Name
+			}
+			err = kubernetes.WaitForObjectsReady(ctx, "Deployment", names, o.minReady(len(names)), func(ctx context.Context, i int) error {
+				_, err := kubernetes.WaitForDeploymentReady(ctx, clientset, &deployments[i])
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error waiting for deployment objects: %s", err)
+			}
+			return nil
+		},
+	},
+	KindPod: {
+		apiVersion: "v1",
+		kind:       "Pod",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			pod, err := kubernetes.ReturnPodObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving pod object: %s", err)
+			}
+			if _, err := kubernetes.WaitForPodReady(ctx, clientset, pod); err != nil {
+				return fmt.Errorf("error waiting for pod object: %s", err)
+			}
+			return nil
+		},
+		waitAll: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			pods, err := kubernetes.ReturnPodObjects(ctx, clientset, o.selector(), o.Namespace, o.MinCount)
+			if err != nil {
+				return fmt.Errorf("error retrieving pod objects: %s", err)
+			}
+			names := make([]string, len(pods))
+			for i := range pods {
+				names[i] = pods[i].Name
+			}
+			err = kubernetes.WaitForObjectsReady(ctx, "Pod", names, o.minReady(len(names)), func(ctx context.Context, i int) error {
+				_, err := kubernetes.WaitForPodReady(ctx, clientset, &pods[i])
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error waiting for pod objects: %s", err)
+			}
+			return nil
+		},
+	},
+	KindStatefulSet: {
+		apiVersion: "apps/v1",
+		kind:       "StatefulSet",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			sts, err := kubernetes.ReturnStatefulSetObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving statefulset object: %s", err)
+			}
+			if _, err := kubernetes.WaitForStatefulSetReady(ctx, clientset, sts, false); err != nil {
+				return fmt.Errorf("error waiting for statefulset object: %s", err)
+			}
+			return nil
+		},
+		waitAll: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			statefulSets, err := kubernetes.ReturnStatefulSetObjects(ctx, clientset, o.selector(), o.Namespace, o.MinCount)
+			if err != nil {
+				return fmt.Errorf("error retrieving statefulset objects: %s", err)
+			}
+			names := make([]string, len(statefulSets))
+			for i := range statefulSets {
+				names[i] = statefulSets[i].Name
+			}
+			err = kubernetes.WaitForObjectsReady(ctx, "StatefulSet", names, o.minReady(len(names)), func(ctx context.Context, i int) error {
+				_, err := kubernetes.WaitForStatefulSetReady(ctx, clientset, &statefulSets[i], false)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error waiting for statefulset objects: %s", err)
+			}
+			return nil
+		},
+	},
+	KindDaemonSet: {
+		apiVersion: "apps/v1",
+		kind:       "DaemonSet",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			daemonSet, err := kubernetes.ReturnDaemonSetObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving daemonset object: %s", err)
+			}
+			if _, err := kubernetes.WaitForDaemonSetReady(ctx, clientset, daemonSet); err != nil {
+				return fmt.Errorf("error waiting for daemonset object: %s", err)
+			}
+			return nil
+		},
+		waitAll: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			daemonSets, err := kubernetes.ReturnDaemonSetObjects(ctx, clientset, o.selector(), o.Namespace, o.MinCount)
+			if err != nil {
+				return fmt.Errorf("error retrieving daemonset objects: %s", err)
+			}
+			names := make([]string, len(daemonSets))
+			for i := range daemonSets {
+				names[i] = daemonSets[i].Name
+			}
+			err = kubernetes.WaitForObjectsReady(ctx, "DaemonSet", names, o.minReady(len(names)), func(ctx context.Context, i int) error {
+				_, err := kubernetes.WaitForDaemonSetReady(ctx, clientset, &daemonSets[i])
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error waiting for daemonset objects: %s", err)
+			}
+			return nil
+		},
+	},
+	KindJob: {
+		apiVersion: "batch/v1",
+		kind:       "Job",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			job, err := kubernetes.ReturnJobObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving job object: %s", err)
+			}
+			if _, err := kubernetes.WaitForJobComplete(ctx, clientset, job, o.LogLines); err != nil {
+				return fmt.Errorf("error waiting for job object: %s", err)
+			}
+			return nil
+		},
+	},
+	KindPVC: {
+		apiVersion: "v1",
+		kind:       "PersistentVolumeClaim",
+		waitOne: func(ctx context.Context, clientset *clientgo.Clientset, o *ObjectOptions) error {
+			pvc, err := kubernetes.ReturnPersistentVolumeClaimObject(ctx, clientset, o.selector(), o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error retrieving pvc object: %s", err)
+			}
+			if _, err := kubernetes.WaitForPersistentVolumeClaimBound(ctx, clientset, pvc); err != nil {
+				return fmt.Errorf("error waiting for pvc object: %s", err)
+			}
+			return nil
+		},
+	},
+}
+
+// Validate checks that the objects are selected in a namespace
+func (o *ObjectOptions) Validate() error {
+	if o.Namespace == "" {
+		return fmt.Errorf("%s requires a namespace", o.kind)
+	}
+	if err := o.selector().Validate(); err != nil {
+		return err
+	}
+	if err := validateFor(o.kind, o.For); err != nil {
+		return err
+	}
+	if o.multiple() && objectKinds[o.kind].waitAll == nil {
+		return fmt.Errorf("%s does not wait on more than one object", o.kind)
+	}
+	if o.LogLines != 0 && o.kind != KindJob {
+		return fmt.Errorf("%s does not print logs", o.kind)
+	}
+	return nil
+}
+
+// Wait waits for the selected objects to be ready or deleted
+func (o *ObjectOptions) Wait(ctx context.Context, c *Clients) error {
+	k := objectKinds[o.kind]
+	if o.For == ForDelete {
+		restConfig, err := c.RestConfig()
+		if err != nil {
+			return err
+		}
+		err = kubernetes.WaitForResourceDeleted(ctx, restConfig, &kubernetes.ResourceWaitOptions{
+			APIVersion:    k.apiVersion,
+			Kind:          k.kind,
+			Namespace:     o.Namespace,
+			Name:          o.Name,
+			Selector:      o.Label,
+			FieldSelector: o.FieldSelector,
+		})
+		if err != nil {
+			return fmt.Errorf("error waiting for %s deletion: %s", k.kind, err)
+		}
+		return nil
+	}
+
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	if o.multiple() {
+		return k.waitAll(ctx, clientset, o)
+	}
+	return k.waitOne(ctx, clientset, o)
+}
+
+// selector returns the selector built from the name, label and field selector
+func (o *ObjectOptions) selector() kubernetes.ObjectSelector {
+	return kubernetes.ObjectSelector{
+		Name:          o.Name,
+		LabelSelector: o.Label,
+		FieldSelector: o.FieldSelector,
+	}
+}
+
+// multiple reports whether every matching object should be waited on
+// instead of only the first one
+func (o *ObjectOptions) multiple() bool {
+	return o.All || o.MinCount > 0
+}
+
+// minReady returns how many of the found objects are required to be ready
+func (o *ObjectOptions) minReady(found int) int {
+	if o.All {
+		return found
+	}
+	return o.MinCount
+}
+
+// Validate checks that the Service is named
+func (o *EndpointsOptions) Validate() error {
+	if o.Namespace == "" || o.Service == "" {
+		return fmt.Errorf("endpoints requires a namespace and a service")
+	}
+	return nil
+}
+
+// Wait waits for the Service to have MinReady ready endpoints
+func (o *EndpointsOptions) Wait(ctx context.Context, c *Clients) error {
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	err = kubernetes.WaitForServiceEndpoints(ctx, clientset, o.Namespace, o.Service, o.PortName, o.MinReady)
+	if err != nil {
+		return fmt.Errorf("error waiting for service endpoints: %s", err)
+	}
+	return nil
+}
+
+// Validate checks that the Ingress or Service is named
+func (o *AddressOptions) Validate() error {
+	if o.Namespace == "" || o.Name == "" {
+		return fmt.Errorf("%s requires a namespace and a name", o.kind)
+	}
+	return nil
+}
+
+// Wait waits for the Ingress or Service to be assigned an address, and writes it to the
+// ConfigMap key when requested
+func (o *AddressOptions) Wait(ctx context.Context, c *Clients) error {
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	switch o.kind {
+	case KindIngress:
+		o.address, err = kubernetes.WaitForIngressAddress(ctx, clientset, o.Namespace, o.Name)
+		if err != nil {
+			return fmt.Errorf("error waiting for ingress address: %s", err)
+		}
+	case KindLoadBalancer:
+		o.address, err = kubernetes.WaitForLoadBalancerAddress(ctx, clientset, o.Namespace, o.Name)
+		if err != nil {
+			return fmt.Errorf("error waiting for load balancer address: %s", err)
+		}
+	}
+
+	if o.ConfigMapName == "" {
+		return nil
+	}
+	namespace := o.ConfigMapNamespace
+	if namespace == "" {
+		namespace = o.Namespace
+	}
+	err = kubernetes.UpdateConfigMapV2(c.InCluster, namespace, o.ConfigMapName, o.ConfigMapKey, o.address)
+	if err != nil {
+		return fmt.Errorf("error writing address to ConfigMap %s/%s: %s", namespace, o.ConfigMapName, err)
+	}
+	return nil
+}
+
+// Address returns the address assigned to the Ingress or Service once Wait has succeeded
+func (o *AddressOptions) Address() string {
+	return o.address
+}
+
+// namedKind waits on one of the kinds of NamedOptions
+type namedKind struct {
+	kind       string
+	namespaced bool
+	wait       func(ctx context.Context, c *Clients, namespace string, name string) error
+}
+
+var namedKinds = map[string]namedKind{
+	KindCRD: {
+		kind: "CustomResourceDefinition",
+		wait: func(ctx context.Context, c *Clients, _ string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForCustomResourceDefinitionEstablished(ctx, restConfig, name)
+		},
+	},
+	KindCertificate: {
+		kind:       "Certificate",
+		namespaced: true,
+		wait: func(ctx context.Context, c *Clients, namespace string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForCertificateReady(ctx, restConfig, namespace, name)
+		},
+	},
+	KindIssuer: {
+		kind:       "Issuer",
+		namespaced: true,
+		wait: func(ctx context.Context, c *Clients, namespace string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForIssuerReady(ctx, restConfig, namespace, name)
+		},
+	},
+	KindClusterIssuer: {
+		kind: "ClusterIssuer",
+		wait: func(ctx context.Context, c *Clients, _ string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForIssuerReady(ctx, restConfig, "", name)
+		},
+	},
+	KindSecretStore: {
+		kind:       "SecretStore",
+		namespaced: true,
+		wait: func(ctx context.Context, c *Clients, namespace string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForSecretStoreReady(ctx, restConfig, namespace, name)
+		},
+	},
+	KindClusterSecretStore: {
+		kind: "ClusterSecretStore",
+		wait: func(ctx context.Context, c *Clients, _ string, name string) error {
+			restConfig, err := c.RestConfig()
+			if err != nil {
+				return err
+			}
+			return kubernetes.WaitForClusterSecretStoreReady(ctx, restConfig, name)
+		},
+	},
+}
+
+// Validate checks that the object is named, in a namespace for namespaced kinds
+func (o *NamedOptions) Validate() error {
+	k := namedKinds[o.kind]
+	switch {
+	case k.namespaced && (o.Namespace == "" || o.Name == ""):
+		return fmt.Errorf("%s requires a namespace and a name", o.kind)
+	case !k.namespaced && o.Name == "":
+		return fmt.Errorf("%s requires a name", o.kind)
+	case !k.namespaced && o.Namespace != "":
+		return fmt.Errorf("%s is cluster scoped and does not accept a namespace", o.kind)
+	}
+	return nil
+}
+
+// Wait waits for the object to be ready
+func (o *NamedOptions) Wait(ctx context.Context, c *Clients) error {
+	k := namedKinds[o.kind]
+	if err := k.wait(ctx, c, o.Namespace, o.Name); err != nil {
+		return fmt.Errorf("error waiting for %s object: %s", k.kind, err)
+	}
+	return nil
+}
+
+// Validate checks that the group version is set
+func (o *APIOptions) Validate() error {
+	if o.GroupVersion == "" {
+		return fmt.Errorf("api requires a group version")
+	}
+	return nil
+}
+
+// Wait waits for the group version to be served
+func (o *APIOptions) Wait(ctx context.Context, c *Clients) error {
+	restConfig, err := c.RestConfig()
+	if err != nil {
+		return err
+	}
+	if err := kubernetes.WaitForAPIGroupVersion(ctx, restConfig, o.GroupVersion); err != nil {
+		return fmt.Errorf("error waiting for API: %s", err)
+	}
+	return nil
+}
+
+// Validate checks that the ExternalSecret is named
+func (o *ExternalSecretOptions) Validate() error {
+	if o.Namespace == "" || o.Name == "" {
+		return fmt.Errorf("external-secret requires a namespace and a name")
+	}
+	return nil
+}
+
+// Wait waits for the ExternalSecret to be synced, and for its Secret when requested
+func (o *ExternalSecretOptions) Wait(ctx context.Context, c *Clients) error {
+	restConfig, err := c.RestConfig()
+	if err != nil {
+		return err
+	}
+	checkSecret := o.CheckSecret || len(o.SecretKeys) > 0
+	err = kubernetes.WaitForExternalSecretSynced(ctx, restConfig, o.Namespace, o.Name, checkSecret, o.SecretKeys)
+	if err != nil {
+		return fmt.Errorf("error waiting for ExternalSecret object: %s", err)
+	}
+	return nil
+}
+
+// Validate checks that the Applications are selected in a namespace
+func (o *ArgoCDAppOptions) Validate() error {
+	if o.Namespace == "" {
+		return fmt.Errorf("argocd-app requires a namespace")
+	}
+	return o.selector().Validate()
+}
+
+// Wait waits for the Applications to reach one of the accepted health and sync statuses
+func (o *ArgoCDAppOptions) Wait(ctx context.Context, c *Clients) error {
+	restConfig, err := c.RestConfig()
+	if err != nil {
+		return err
+	}
+	err = kubernetes.WaitForArgoCDApplicationReady(ctx, restConfig, o.Namespace, o.selector(), o.Health, o.Sync)
+	if err != nil {
+		return fmt.Errorf("error waiting for Argo CD Application object: %s", err)
+	}
+	return nil
+}
+
+// selector returns the selector built from the name, label and field selector
+func (o *ArgoCDAppOptions) selector() kubernetes.ObjectSelector {
+	return kubernetes.ObjectSelector{
+		Name:          o.Name,
+		LabelSelector: o.Label,
+		FieldSelector: o.FieldSelector,
+	}
+}
+
+// Validate checks that the key is named and only one of value or value regex is set
+func (o *KeyOptions) Validate() error {
+	if o.Namespace == "" || o.Name == "" || o.Key == "" {
+		return fmt.Errorf("%s requires a namespace, a name and a key", o.kind)
+	}
+	if o.Value != "" && o.ValueRegex != "" {
+		return fmt.Errorf("%s accepts only one of a value or a value regex", o.kind)
+	}
+	return nil
+}
+
+// Wait waits for the Secret or ConfigMap key to be set
+func (o *KeyOptions) Wait(ctx context.Context, c *Clients) error {
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	keyWaitOptions := &kubernetes.KeyWaitOptions{
+		Namespace:  o.Namespace,
+		Name:       o.Name,
+		Key:        o.Key,
+		Value:      o.Value,
+		ValueRegex: o.ValueRegex,
+	}
+	if o.kind == KindConfigMap {
+		err = kubernetes.WaitForConfigMapKey(ctx, clientset, keyWaitOptions)
+		if err != nil {
+			return fmt.Errorf("error waiting for ConfigMap key: %s", err)
+		}
+		return nil
+	}
+	err = kubernetes.WaitForSecretKey(ctx, clientset, keyWaitOptions)
+	if err != nil {
+		return fmt.Errorf("error waiting for Secret key: %s", err)
+	}
+	return nil
+}
+
+// Validate checks that the resources are selected and, unless waiting for their deletion, that
+// exactly one of a condition or a JSONPath is set
+func (o *ResourceOptions) Validate() error {
+	if o.APIVersion == "" || o.Kind == "" {
+		return fmt.Errorf("resource requires an API version and a kind")
+	}
+	if o.Name == "" && o.Selector == "" && o.FieldSelector == "" {
+		return fmt.Errorf("resource requires at least one of a name, a selector or a field selector")
+	}
+	if err := validateFor(KindResource, o.For); err != nil {
+		return err
+	}
+	if o.For != ForDelete && (o.Condition == "") == (o.JSONPath == "") {
+		return fmt.Errorf("resource requires exactly one of a condition or a JSONPath")
+	}
+	return nil
+}
+
+// Wait waits for the resources to reach the condition or JSONPath value, or to be deleted
+func (o *ResourceOptions) Wait(ctx context.Context, c *Clients) error {
+	restConfig, err := c.RestConfig()
+	if err != nil {
+		return err
+	}
+	resourceWaitOptions := &kubernetes.ResourceWaitOptions{
+		APIVersion:    o.APIVersion,
+		Kind:          o.Kind,
+		Namespace:     o.Namespace,
+		Name:          o.Name,
+		Selector:      o.Selector,
+		FieldSelector: o.FieldSelector,
+		Condition:     o.Condition,
+		JSONPath:      o.JSONPath,
+		JSONPathValue: o.JSONPathValue,
+	}
+	if o.For == ForDelete {
+		if err := kubernetes.WaitForResourceDeleted(ctx, restConfig, resourceWaitOptions); err != nil {
+			return fmt.Errorf("error waiting for %s deletion: %s", o.Kind, err)
+		}
+		return nil
+	}
+	if err := kubernetes.WaitForResourceReady(ctx, restConfig, resourceWaitOptions); err != nil {
+		return fmt.Errorf("error waiting for %s object: %s", o.Kind, err)
+	}
+	return nil
+}
+
+// Validate checks that the Namespace is named
+func (o *NamespaceOptions) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("namespace requires a name")
+	}
+	return validateFor(KindNamespace, o.For)
+}
+
+// Wait waits for the Namespace to be active or deleted
+func (o *NamespaceOptions) Wait(ctx context.Context, c *Clients) error {
+	restConfig, err := c.RestConfig()
+	if err != nil {
+		return err
+	}
+	resourceWaitOptions := &kubernetes.ResourceWaitOptions{
+		APIVersion:    "v1",
+		Kind:          "Namespace",
+		Name:          o.Name,
+		JSONPath:      "{.status.phase}",
+		JSONPathValue: string(v1.NamespaceActive),
+	}
+	if o.For == ForDelete {
+		if err := kubernetes.WaitForResourceDeleted(ctx, restConfig, resourceWaitOptions); err != nil {
+			return fmt.Errorf("error waiting for Namespace deletion: %s", err)
+		}
+		return nil
+	}
+	if err := kubernetes.WaitForResourceReady(ctx, restConfig, resourceWaitOptions); err != nil {
+		return fmt.Errorf("error waiting for Namespace object: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+
+	miniointernal "github.com/konstructio/kubernetes-toolkit/internal/minio"
+	"github.com/minio/minio-go/v7"
+)
+
+// Validate checks that the credentials Secret is namespaced
+func (o *MinioBucketsOptions) Validate() error {
+	return validateCredentials(KindMinioBuckets, o.Credentials)
+}
+
+// Wait waits for every bucket to exist
+func (o *MinioBucketsOptions) Wait(ctx context.Context, c *Clients) error {
+	minioClient, err := newMinioClient(&o.Config, c)
+	if err != nil {
+		return err
+	}
+	return miniointernal.WaitForBuckets(ctx, minioClient, o.Buckets)
+}
+
+// Validate checks that the object is either a key or a prefix in a bucket
+func (o *MinioObjectOptions) Validate() error {
+	if o.Bucket == "" || (o.Key == "") == (o.Prefix == "") {
+		return fmt.Errorf("%s requires a bucket and exactly one of a key or a prefix", KindMinioObject)
+	}
+	if o.NewerThan != "" {
+		if _, err := miniointernal.ParseNewerThan(o.NewerThan); err != nil {
+			return fmt.Errorf("%s requires newer than to be %s", KindMinioObject, err)
+		}
+	}
+	return validateCredentials(KindMinioObject, o.Credentials)
+}
+
+// Wait waits for the object to exist
+func (o *MinioObjectOptions) Wait(ctx context.Context, c *Clients) error {
+	objectWaitOptions := &miniointernal.ObjectWaitOptions{
+		Bucket:  o.Bucket,
+		Key:     o.Key,
+		Prefix:  o.Prefix,
+		MinSize: o.MinSize,
+	}
+	if o.NewerThan != "" {
+		newerThan, err := miniointernal.ParseNewerThan(o.NewerThan)
+		if err != nil {
+			return err
+		}
+		objectWaitOptions.NewerThan = newerThan
+	}
+
+	minioClient, err := newMinioClient(&o.Config, c)
+	if err != nil {
+		return err
+	}
+	if err := miniointernal.WaitForObject(ctx, minioClient, objectWaitOptions); err != nil {
+		return fmt.Errorf("error waiting for minio object: %s", err)
+	}
+	return nil
+}
+
+// validateCredentials checks that a credentials Secret is set together with its namespace
+func validateCredentials(kind string, credentials miniointernal.Credentials) error {
+	if (credentials.SecretName == "") != (credentials.SecretNamespace == "") {
+		return fmt.Errorf("%s requires both the name and the namespace of the credentials Secret, or neither", kind)
+	}
+	return nil
+}
+
+// newMinioClient returns a minio client, only loading the kubeconfig when the credentials are
+// read from a Secret
+func newMinioClient(config *miniointernal.Config, c *Clients) (*minio.Client, error) {
+	var inCluster string
+	if config.Credentials.SecretName != "" {
+		var err error
+		inCluster, err = c.kubeConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return miniointernal.NewClient(config, inCluster)
+}
@@ -0,0 +1,103 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/probe"
+)
+
+// Validate checks that the URL is set and that the CA bundle and client certificate are read
+// from a single, namespaced source
+func (o *HTTPOptions) Validate() error {
+	if o.URL == "" {
+		return fmt.Errorf("http requires a URL")
+	}
+	if o.CASecret != "" && o.CAConfigMap != "" {
+		return fmt.Errorf("http accepts only one of a CA Secret or a CA ConfigMap")
+	}
+	if o.TLSNamespace == "" && o.readsKubernetes() {
+		return fmt.Errorf("http requires the namespace of the CA bundle and client certificate Secret or ConfigMap")
+	}
+	return nil
+}
+
+// Wait waits for the endpoint to return the expected status and body
+func (o *HTTPOptions) Wait(ctx context.Context, c *Clients) error {
+	httpOptions := &probe.HTTPOptions{
+		URL:                o.URL,
+		ExpectStatus:       o.ExpectStatus,
+		ExpectBodyRegex:    o.ExpectBodyRegex,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	// The kubeconfig is only loaded when the CA bundle or client certificate is read from Kubernetes
+	var inCluster string
+	if o.readsKubernetes() {
+		var err error
+		inCluster, err = c.kubeConfig()
+		if err != nil {
+			return err
+		}
+	}
+	tlsSource := probe.TLSSource{
+		Namespace:        o.TLSNamespace,
+		CASecret:         o.CASecret,
+		CAConfigMap:      o.CAConfigMap,
+		CAKey:            o.CAKey,
+		ClientCertSecret: o.ClientCertSecret,
+		ClientCertFile:   o.ClientCertFile,
+		ClientKeyFile:    o.ClientKeyFile,
+	}
+	if err := tlsSource.Load(inCluster, httpOptions); err != nil {
+		return err
+	}
+
+	if err := probe.WaitForHTTP(ctx, httpOptions); err != nil {
+		return fmt.Errorf("error waiting for http endpoint: %s", err)
+	}
+	return nil
+}
+
+// readsKubernetes reports whether the CA bundle or client certificate is read from Kubernetes
+func (o *HTTPOptions) readsKubernetes() bool {
+	return o.CASecret != "" || o.CAConfigMap != "" || o.ClientCertSecret != ""
+}
+
+// Validate checks that the address is set
+func (o *TCPOptions) Validate() error {
+	if o.Address == "" {
+		return fmt.Errorf("tcp requires an address")
+	}
+	return nil
+}
+
+// Wait waits for the address to accept connections
+func (o *TCPOptions) Wait(ctx context.Context, _ *Clients) error {
+	if err := probe.WaitForTCP(ctx, o.Address); err != nil {
+		return fmt.Errorf("error waiting for tcp address: %s", err)
+	}
+	return nil
+}
+
+// Validate checks that the name is set
+func (o *DNSOptions) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("dns requires a name")
+	}
+	return nil
+}
+
+// Wait waits for the name to resolve to the expected records
+func (o *DNSOptions) Wait(ctx context.Context, _ *Clients) error {
+	err := probe.WaitForDNS(ctx, &probe.DNSOptions{
+		Name:       o.Name,
+		RecordType: o.RecordType,
+		Expect:     o.Expect,
+		Nameserver: o.Nameserver,
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for dns record: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package waiter
+
+import (
+	"sync"
+
+	miniointernal "github.com/konstructio/kubernetes-toolkit/internal/minio"
+	clientgo "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	KindDeployment         = "deployment"
+	KindPod                = "pod"
+	KindStatefulSet        = "statefulset"
+	KindDaemonSet          = "daemonset"
+	KindJob                = "job"
+	KindPVC                = "pvc"
+	KindEndpoints          = "endpoints"
+	KindIngress            = "ingress"
+	KindLoadBalancer       = "loadbalancer"
+	KindCRD                = "crd"
+	KindAPI                = "api"
+	KindCertificate        = "certificate"
+	KindIssuer             = "issuer"
+	KindClusterIssuer      = "cluster-issuer"
+	KindClusterSecretStore = "cluster-secret-store"
+	KindSecretStore        = "secret-store"
+	KindExternalSecret     = "external-secret"
+	KindArgoCDApp          = "argocd-app"
+	KindSecret             = "secret"
+	KindConfigMap          = "configmap"
+	KindResource           = "resource"
+	KindNamespace          = "namespace"
+	KindHTTP               = "http"
+	KindTCP                = "tcp"
+	KindDNS                = "dns"
+	KindMinioBuckets       = "minio-buckets"
+	KindMinioObject        = "minio-object"
+	KindVaultUnseal        = "vault-unseal"
+	KindVaultInitComplete  = "vault-init-complete"
+
+	ForReady  = "ready"
+	ForDelete = "delete"
+)
+
+// Clients creates the Kubernetes clients on first use and shares them between waiters
+type Clients struct {
+	// InCluster is true to authenticate with the Pod service account, false to use the local kubeconfig
+	InCluster string
+
+	once       sync.Once
+	restConfig *rest.Config
+	clientset  *clientgo.Clientset
+	err        error
+}
+
+// ObjectOptions selects the Deployments, Pods, StatefulSets, DaemonSets, Jobs or
+// PersistentVolumeClaims to wait on
+type ObjectOptions struct {
+	kind          string
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"resourceName,omitempty"`
+	Label         string `json:"label,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	// For is either ready or delete
+	For string `json:"for,omitempty"`
+	// All waits for every matching object, MinCount for at least that many of them
+	All      bool `json:"all,omitempty"`
+	MinCount int  `json:"minCount,omitempty"`
+	// LogLines is the number of log lines printed from the failed Pods of a Job
+	LogLines int64 `json:"logLines,omitempty"`
+}
+
+// EndpointsOptions describes the Service whose EndpointSlices must contain MinReady ready addresses
+type EndpointsOptions struct {
+	Namespace string `json:"namespace,omitempty"`
+	Service   string `json:"service,omitempty"`
+	PortName  string `json:"portName,omitempty"`
+	MinReady  int    `json:"minReady,omitempty"`
+}
+
+// AddressOptions describes the Ingress or LoadBalancer Service to wait on for an address, and
+// the ConfigMap key the address is written to when ConfigMapName is set
+type AddressOptions struct {
+	kind               string
+	address            string
+	Namespace          string `json:"namespace,omitempty"`
+	Name               string `json:"resourceName,omitempty"`
+	ConfigMapNamespace string `json:"configMapNamespace,omitempty"`
+	ConfigMapName      string `json:"configMapName,omitempty"`
+	ConfigMapKey       string `json:"configMapKey,omitempty"`
+}
+
+// NamedOptions names the CustomResourceDefinition, cert-manager Certificate or issuer, or
+// External Secrets Operator secret store to wait on. Namespace is only set for namespaced kinds
+type NamedOptions struct {
+	kind      string
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"resourceName,omitempty"`
+}
+
+// APIOptions describes the API group version that must be served
+type APIOptions struct {
+	GroupVersion string `json:"groupVersion,omitempty"`
+}
+
+// ExternalSecretOptions names the ExternalSecret to wait on. CheckSecret also waits for the
+// synced Secret, which is implied by SecretKeys
+type ExternalSecretOptions struct {
+	Namespace   string   `json:"namespace,omitempty"`
+	Name        string   `json:"resourceName,omitempty"`
+	CheckSecret bool     `json:"checkSecret,omitempty"`
+	SecretKeys  []string `json:"secretKeys,omitempty"`
+}
+
+// ArgoCDAppOptions selects the Argo CD Applications to wait on and the accepted health and
+// sync statuses, the sync status is ignored when Sync is empty
+type ArgoCDAppOptions struct {
+	Namespace     string   `json:"namespace,omitempty"`
+	Name          string   `json:"resourceName,omitempty"`
+	Label         string   `json:"label,omitempty"`
+	FieldSelector string   `json:"fieldSelector,omitempty"`
+	Health        []string `json:"health,omitempty"`
+	Sync          []string `json:"sync,omitempty"`
+}
+
+// KeyOptions describes the Secret or ConfigMap key to wait on
+type KeyOptions struct {
+	kind       string
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"resourceName,omitempty"`
+	Key        string `json:"key,omitempty"`
+	Value      string `json:"value,omitempty"`
+	ValueRegex string `json:"valueRegex,omitempty"`
+}
+
+// ResourceOptions selects any resource and the status condition or JSONPath value to wait for
+type ResourceOptions struct {
+	APIVersion    string `json:"apiVersion,omitempty"`
+	Kind          string `json:"kind,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"resourceName,omitempty"`
+	Selector      string `json:"selector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+	Condition     string `json:"condition,omitempty"`
+	JSONPath      string `json:"jsonPath,omitempty"`
+	JSONPathValue string `json:"jsonPathValue,omitempty"`
+	For           string `json:"for,omitempty"`
+}
+
+// NamespaceOptions names the Namespace to wait on to be active or deleted
+type NamespaceOptions struct {
+	Name string `json:"resourceName,omitempty"`
+	For  string `json:"for,omitempty"`
+}
+
+// HTTPOptions describes the HTTP endpoint to probe, and where its CA bundle and client
+// certificate are read from
+type HTTPOptions struct {
+	URL                string `json:"url,omitempty"`
+	ExpectStatus       int    `json:"expectStatus,omitempty"`
+	ExpectBodyRegex    string `json:"expectBodyRegex,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	TLSNamespace       string `json:"tlsNamespace,omitempty"`
+	CASecret           string `json:"caSecret,omitempty"`
+	CAConfigMap        string `json:"caConfigMap,omitempty"`
+	CAKey              string `json:"caKey,omitempty"`
+	ClientCertSecret   string `json:"clientCertSecret,omitempty"`
+	ClientCertFile     string `json:"clientCertFile,omitempty"`
+	ClientKeyFile      string `json:"clientKeyFile,omitempty"`
+}
+
+// TCPOptions describes the host:port address that must accept connections
+type TCPOptions struct {
+	Address string `json:"address,omitempty"`
+}
+
+// DNSOptions describes the DNS name to resolve and the records to expect
+type DNSOptions struct {
+	Name       string   `json:"dnsName,omitempty"`
+	RecordType string   `json:"recordType,omitempty"`
+	Expect     []string `json:"expect,omitempty"`
+	Nameserver string   `json:"nameserver,omitempty"`
+}
+
+// MinioBucketsOptions describes the minio endpoint and the buckets to wait for
+type MinioBucketsOptions struct {
+	miniointernal.Config
+}
+
+// MinioObjectOptions describes the minio endpoint and the object to wait for, either a Key or
+// any object under a Prefix
+type MinioObjectOptions struct {
+	miniointernal.Config
+	Bucket  string `json:"bucket,omitempty"`
+	Key     string `json:"objectKey,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+	MinSize int64  `json:"minSize,omitempty"`
+	// NewerThan is an RFC3339 timestamp, or a duration before now such as 1h
+	NewerThan string `json:"newerThan,omitempty"`
+}
+
+// VaultUnsealOptions describes the Vault instances to check the seal status of
+type VaultUnsealOptions struct {
+	Addresses          []string `json:"addresses,omitempty"`
+	Namespace          string   `json:"namespace,omitempty"`
+	Label              string   `json:"label,omitempty"`
+	Scheme             string   `json:"scheme,omitempty"`
+	Port               int      `json:"port,omitempty"`
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty"`
+	Quorum             int      `json:"quorum,omitempty"`
+}
+
+// VaultInitCompleteOptions describes the Vault secrets that must be readable and how to
+// authenticate to read them
+type VaultInitCompleteOptions struct {
+	Address                 string   `json:"address,omitempty"`
+	Paths                   []string `json:"paths,omitempty"`
+	KVMount                 string   `json:"kvMount,omitempty"`
+	KVVersion               int      `json:"kvVersion,omitempty"`
+	AuthMethod              string   `json:"authMethod,omitempty"`
+	TokenEnv                string   `json:"tokenEnv,omitempty"`
+	TokenFile               string   `json:"tokenFile,omitempty"`
+	TokenSecretNamespace    string   `json:"tokenSecretNamespace,omitempty"`
+	TokenSecretName         string   `json:"tokenSecretName,omitempty"`
+	TokenSecretKey          string   `json:"tokenSecretKey,omitempty"`
+	KubernetesRole          string   `json:"kubernetesRole,omitempty"`
+	KubernetesMount         string   `json:"kubernetesMount,omitempty"`
+	ServiceAccountTokenFile string   `json:"serviceAccountTokenFile,omitempty"`
+}
@@ -0,0 +1,75 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+
+	vaultinternal "github.com/konstructio/kubernetes-toolkit/internal/vault"
+)
+
+// Validate checks that the quorum is not negative, every instance must be unsealed when it is 0
+func (o *VaultUnsealOptions) Validate() error {
+	if o.Quorum < 0 {
+		return fmt.Errorf("%s requires a quorum of 0 or more", KindVaultUnseal)
+	}
+	return nil
+}
+
+// Wait waits for the vault instances to be unsealed
+func (o *VaultUnsealOptions) Wait(ctx context.Context, c *Clients) error {
+	unsealWaitOptions := &vaultinternal.UnsealWaitOptions{
+		Addresses:          o.Addresses,
+		Namespace:          o.Namespace,
+		LabelSelector:      o.Label,
+		Scheme:             o.Scheme,
+		Port:               o.Port,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		Quorum:             o.Quorum,
+	}
+	if len(o.Addresses) > 0 {
+		return vaultinternal.WaitForUnseal(ctx, nil, unsealWaitOptions)
+	}
+
+	// The vault pods are only listed when no address is provided
+	clientset, err := c.Clientset()
+	if err != nil {
+		return err
+	}
+	return vaultinternal.WaitForUnseal(ctx, clientset, unsealWaitOptions)
+}
+
+// Validate checks that the token is read from a single source
+func (o *VaultInitCompleteOptions) Validate() error {
+	if o.TokenFile != "" && o.TokenSecretName != "" {
+		return fmt.Errorf("%s accepts only one of a token file or a token Secret", KindVaultInitComplete)
+	}
+	return nil
+}
+
+// Wait waits for every secret path to be readable
+func (o *VaultInitCompleteOptions) Wait(ctx context.Context, c *Clients) error {
+	initCompleteWaitOptions := &vaultinternal.InitCompleteWaitOptions{
+		Address:                 o.Address,
+		Paths:                   o.Paths,
+		KVMount:                 o.KVMount,
+		KVVersion:               o.KVVersion,
+		AuthMethod:              o.AuthMethod,
+		TokenEnv:                o.TokenEnv,
+		TokenFile:               o.TokenFile,
+		TokenSecretNamespace:    o.TokenSecretNamespace,
+		TokenSecretName:         o.TokenSecretName,
+		TokenSecretKey:          o.TokenSecretKey,
+		KubernetesRole:          o.KubernetesRole,
+		KubernetesMount:         o.KubernetesMount,
+		ServiceAccountTokenFile: o.ServiceAccountTokenFile,
+	}
+	// The kubeconfig is only loaded when the token is read from a Secret
+	if o.TokenSecretName != "" {
+		inCluster, err := c.kubeConfig()
+		if err != nil {
+			return err
+		}
+		initCompleteWaitOptions.InCluster = inCluster
+	}
+	return vaultinternal.WaitForInitComplete(ctx, initCompleteWaitOptions)
+}
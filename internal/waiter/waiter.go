@@ -0,0 +1,196 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konstructio/kubernetes-toolkit/internal/kubernetes"
+	miniointernal "github.com/konstructio/kubernetes-toolkit/internal/minio"
+	"github.com/konstructio/kubernetes-toolkit/internal/probe"
+	vaultinternal "github.com/konstructio/kubernetes-toolkit/internal/vault"
+	clientgo "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Waiter waits on one kind of thing, both the wait-for subcommands and plan targets build one
+type Waiter interface {
+	// Validate checks that the options required by the kind are set
+	Validate() error
+	// Wait blocks until the thing is ready, or until ctx is done
+	Wait(ctx context.Context, c *Clients) error
+}
+
+// kinds maps every kind to a function returning its options set to their defaults
+var kinds = map[string]func() Waiter{
+	KindDeployment:         func() Waiter { return NewObjectOptions(KindDeployment) },
+	KindPod:                func() Waiter { return NewObjectOptions(KindPod) },
+	KindStatefulSet:        func() Waiter { return NewObjectOptions(KindStatefulSet) },
+	KindDaemonSet:          func() Waiter { return NewObjectOptions(KindDaemonSet) },
+	KindJob:                func() Waiter { return NewObjectOptions(KindJob) },
+	KindPVC:                func() Waiter { return NewObjectOptions(KindPVC) },
+	KindEndpoints:          func() Waiter { return NewEndpointsOptions() },
+	KindIngress:            func() Waiter { return NewAddressOptions(KindIngress) },
+	KindLoadBalancer:       func() Waiter { return NewAddressOptions(KindLoadBalancer) },
+	KindCRD:                func() Waiter { return NewNamedOptions(KindCRD) },
+	KindAPI:                func() Waiter { return &APIOptions{} },
+	KindCertificate:        func() Waiter { return NewNamedOptions(KindCertificate) },
+	KindIssuer:             func() Waiter { return NewNamedOptions(KindIssuer) },
+	KindClusterIssuer:      func() Waiter { return NewNamedOptions(KindClusterIssuer) },
+	KindClusterSecretStore: func() Waiter { return NewNamedOptions(KindClusterSecretStore) },
+	KindSecretStore:        func() Waiter { return NewNamedOptions(KindSecretStore) },
+	KindExternalSecret:     func() Waiter { return &ExternalSecretOptions{} },
+	KindArgoCDApp:          func() Waiter { return NewArgoCDAppOptions() },
+	KindSecret:             func() Waiter { return NewKeyOptions(KindSecret) },
+	KindConfigMap:          func() Waiter { return NewKeyOptions(KindConfigMap) },
+	KindResource:           func() Waiter { return NewResourceOptions() },
+	KindNamespace:          func() Waiter { return NewNamespaceOptions() },
+	KindHTTP:               func() Waiter { return NewHTTPOptions() },
+	KindTCP:                func() Waiter { return &TCPOptions{} },
+	KindDNS:                func() Waiter { return NewDNSOptions() },
+	KindMinioBuckets:       func() Waiter { return NewMinioBucketsOptions() },
+	KindMinioObject:        func() Waiter { return NewMinioObjectOptions() },
+	KindVaultUnseal:        func() Waiter { return NewVaultUnsealOptions() },
+	KindVaultInitComplete:  func() Waiter { return NewVaultInitCompleteOptions() },
+}
+
+// New returns the options of a kind set to their defaults
+func New(kind string) (Waiter, error) {
+	newOptions, exists := kinds[kind]
+	if !exists {
+		return nil, fmt.Errorf("unknown kind %q", kind)
+	}
+	return newOptions(), nil
+}
+
+// NewClients returns the clients of the cluster, which are only created once a waiter needs them
+func NewClients(inCluster string) *Clients {
+	return &Clients{InCluster: inCluster}
+}
+
+// RestConfig returns the rest config of the cluster, or the error that prevented creating it
+func (c *Clients) RestConfig() (*rest.Config, error) {
+	c.load()
+	return c.restConfig, c.err
+}
+
+// Clientset returns the clientset of the cluster, or the error that prevented creating it
+func (c *Clients) Clientset() (*clientgo.Clientset, error) {
+	c.load()
+	return c.clientset, c.err
+}
+
+// kubeConfig returns InCluster once the kubeconfig is known to load, for the helpers that read
+// Secrets and ConfigMaps with their own clientset
+func (c *Clients) kubeConfig() (string, error) {
+	c.load()
+	return c.InCluster, c.err
+}
+
+// load creates the clients the first time they are needed
+func (c *Clients) load() {
+	c.once.Do(func() {
+		c.restConfig, c.clientset, c.err = kubernetes.NewKubeConfig(c.InCluster)
+	})
+}
+
+// validateFor checks that for is either ready or delete
+func validateFor(kind string, value string) error {
+	switch value {
+	case "", ForReady, ForDelete:
+		return nil
+	}
+	return fmt.Errorf("%s accepts %s or %s to wait for, not %s", kind, ForReady, ForDelete, value)
+}
+
+// NewObjectOptions returns the options of a Deployment, Pod, StatefulSet, DaemonSet, Job or
+// PersistentVolumeClaim kind
+func NewObjectOptions(kind string) *ObjectOptions {
+	o := &ObjectOptions{kind: kind, For: ForReady}
+	if kind == KindJob {
+		o.LogLines = 20
+	}
+	return o
+}
+
+// NewEndpointsOptions returns endpoints options requiring a single ready address
+func NewEndpointsOptions() *EndpointsOptions {
+	return &EndpointsOptions{MinReady: 1}
+}
+
+// NewAddressOptions returns the options of the ingress or loadbalancer kind
+func NewAddressOptions(kind string) *AddressOptions {
+	return &AddressOptions{kind: kind, ConfigMapKey: "address"}
+}
+
+// NewNamedOptions returns the options of a CustomResourceDefinition, cert-manager or External
+// Secrets Operator kind
+func NewNamedOptions(kind string) *NamedOptions {
+	return &NamedOptions{kind: kind}
+}
+
+// NewArgoCDAppOptions returns Argo CD Application options accepting Healthy and Synced
+func NewArgoCDAppOptions() *ArgoCDAppOptions {
+	return &ArgoCDAppOptions{Health: []string{"Healthy"}, Sync: []string{"Synced"}}
+}
+
+// NewKeyOptions returns the options of the secret or configmap kind
+func NewKeyOptions(kind string) *KeyOptions {
+	return &KeyOptions{kind: kind}
+}
+
+// NewResourceOptions returns resource options waiting for readiness
+func NewResourceOptions() *ResourceOptions {
+	return &ResourceOptions{For: ForReady}
+}
+
+// NewNamespaceOptions returns Namespace options waiting for the Namespace to be active
+func NewNamespaceOptions() *NamespaceOptions {
+	return &NamespaceOptions{For: ForReady}
+}
+
+// NewHTTPOptions returns HTTP options expecting a 200 status code
+func NewHTTPOptions() *HTTPOptions {
+	return &HTTPOptions{ExpectStatus: 200, CAKey: probe.DefaultCAKey}
+}
+
+// NewDNSOptions returns DNS options resolving A records
+func NewDNSOptions() *DNSOptions {
+	return &DNSOptions{RecordType: "A"}
+}
+
+// NewMinioBucketsOptions returns the options waiting for the buckets of the kubefirst platform
+func NewMinioBucketsOptions() *MinioBucketsOptions {
+	return &MinioBucketsOptions{Config: *miniointernal.DefaultConfig()}
+}
+
+// NewMinioObjectOptions returns the options connecting to the minio of the kubefirst platform
+func NewMinioObjectOptions() *MinioObjectOptions {
+	return &MinioObjectOptions{Config: *miniointernal.DefaultConfig()}
+}
+
+// NewVaultUnsealOptions returns the options checking every vault pod of the kubefirst platform
+func NewVaultUnsealOptions() *VaultUnsealOptions {
+	return &VaultUnsealOptions{
+		Namespace: vaultinternal.DefaultNamespace,
+		Label:     vaultinternal.DefaultLabelSelector,
+		Scheme:    vaultinternal.DefaultScheme,
+		Port:      vaultinternal.DefaultPort,
+	}
+}
+
+// NewVaultInitCompleteOptions returns the options reading the secret written by the kubefirst
+// platform terraform with a token
+func NewVaultInitCompleteOptions() *VaultInitCompleteOptions {
+	return &VaultInitCompleteOptions{
+		Address:                 vaultinternal.DefaultAddress,
+		Paths:                   []string{vaultinternal.DefaultCheckPath},
+		KVMount:                 vaultinternal.DefaultKVMount,
+		KVVersion:               vaultinternal.DefaultKVVersion,
+		AuthMethod:              vaultinternal.AuthMethodToken,
+		TokenEnv:                vaultinternal.DefaultTokenEnv,
+		TokenSecretNamespace:    vaultinternal.DefaultNamespace,
+		TokenSecretKey:          vaultinternal.DefaultTokenSecretKey,
+		KubernetesMount:         vaultinternal.DefaultKubernetesMount,
+		ServiceAccountTokenFile: vaultinternal.DefaultServiceAccountTokenFile,
+	}
+}
@@ -0,0 +1,129 @@
+package waiter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	objectKeyWithCredentials := &MinioObjectOptions{Bucket: "k1-state-store", Key: "terraform.tfstate"}
+	objectKeyWithCredentials.Credentials.SecretNamespace = "minio"
+	objectKeyWithCredentials.Credentials.SecretName = "minio-creds"
+	bucketsWithoutCredentialsNamespace := &MinioBucketsOptions{}
+	bucketsWithoutCredentialsNamespace.Credentials.SecretName = "minio-creds"
+
+	tests := []struct {
+		name    string
+		options Waiter
+		wantErr bool
+	}{
+		{
+			name:    "deployment",
+			options: &ObjectOptions{kind: KindDeployment, Namespace: "default", Name: "metaphor"},
+		},
+		{
+			name:    "deployment without selector",
+			options: &ObjectOptions{kind: KindDeployment, Namespace: "default"},
+			wantErr: true,
+		},
+		{
+			name:    "pvc with min count",
+			options: &ObjectOptions{kind: KindPVC, Namespace: "default", Name: "data", MinCount: 2},
+			wantErr: true,
+		},
+		{
+			name:    "unknown for",
+			options: &ObjectOptions{kind: KindPod, Namespace: "default", Name: "metaphor", For: "running"},
+			wantErr: true,
+		},
+		{
+			name:    "cluster issuer with namespace",
+			options: &NamedOptions{kind: KindClusterIssuer, Namespace: "default", Name: "letsencrypt-prod"},
+			wantErr: true,
+		},
+		{
+			name:    "resource deletion without condition",
+			options: &ResourceOptions{APIVersion: "v1", Kind: "Secret", Name: "metaphor", For: ForDelete},
+		},
+		{
+			name:    "resource without condition",
+			options: &ResourceOptions{APIVersion: "v1", Kind: "Secret", Name: "metaphor"},
+			wantErr: true,
+		},
+		{
+			name:    "http CA Secret without namespace",
+			options: &HTTPOptions{URL: "https://vault.example.com", CASecret: "vault-tls"},
+			wantErr: true,
+		},
+		{
+			name:    "dns",
+			options: &DNSOptions{Name: "vault.vault.svc.cluster.local"},
+		},
+		{
+			name:    "minio object with credentials",
+			options: objectKeyWithCredentials,
+		},
+		{
+			name:    "invalid newer than",
+			options: &MinioObjectOptions{Bucket: "k1-state-store", Key: "terraform.tfstate", NewerThan: "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "credentials secret without namespace",
+			options: bucketsWithoutCredentialsNamespace,
+			wantErr: true,
+		},
+		{
+			name:    "vault quorum",
+			options: &VaultUnsealOptions{Quorum: 2},
+		},
+		{
+			name:    "token file and token secret",
+			options: &VaultInitCompleteOptions{TokenFile: "/vault/token", TokenSecretName: "vault-unseal-secret"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	w, err := New(KindJob)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := w.(*ObjectOptions).LogLines; got != 20 {
+		t.Errorf("expected the default log lines of a job, got %v", got)
+	}
+
+	w, err = New(KindMinioBuckets)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := w.(*MinioBucketsOptions); got.Region == "" || len(got.Buckets) == 0 {
+		t.Errorf("expected the default minio region and buckets, got %+v", got.Config)
+	}
+
+	if _, err := New("unknown"); err == nil {
+		t.Errorf("New() expected an error for an unknown kind")
+	}
+}
+
+func TestClientsError(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/does/not/exist")
+	c := NewClients("false")
+
+	// A kubeconfig that cannot be loaded fails the waiter instead of exiting
+	err := NewEndpointsOptions().Wait(context.Background(), c)
+	if err == nil {
+		t.Fatalf("Wait() expected an error for a missing kubeconfig")
+	}
+	if _, err := c.RestConfig(); err == nil {
+		t.Errorf("RestConfig() expected the same error")
+	}
+}